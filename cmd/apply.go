@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"os/exec"
@@ -8,7 +9,10 @@ import (
 
 	"github.com/chmouel/gh-prreview/pkg/ai"
 	"github.com/chmouel/gh-prreview/pkg/applier"
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/deferred"
 	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -22,10 +26,11 @@ var (
 	applyAIModel      string
 	applyAITemplate   string
 	applyAIToken      string
+	applyDeferResolve bool
 )
 
 var applyCmd = &cobra.Command{
-	Use:   "apply [PR_NUMBER]",
+	Use:   "apply [PR]",
 	Short: "Apply review suggestions to local files",
 	Long:  `Apply GitHub review suggestions to your local files interactively or in batch mode.`,
 	RunE:  runApply,
@@ -36,6 +41,7 @@ func init() {
 	applyCmd.Flags().StringVar(&applyFile, "file", "", "Only apply suggestions for a specific file")
 	applyCmd.Flags().BoolVar(&applyShowResolved, "include-resolved", false, "Include resolved/done suggestions")
 	applyCmd.Flags().BoolVar(&applyDebug, "debug", false, "Enable debug output")
+	applyCmd.Flags().BoolVar(&applyDeferResolve, "defer-resolve", false, "Resolve the addressed threads only once the fix is pushed, with the finish command")
 
 	// AI flags
 	applyCmd.Flags().BoolVar(&applyAIAuto, "ai-auto", false, "Automatically apply all suggestions using AI")
@@ -51,11 +57,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	client := github.NewClient()
-	client.SetDebug(applyDebug)
-	if repoFlag != "" {
-		client.SetRepo(repoFlag)
-	}
+	client := newClient(applyDebug)
 
 	prNumber, err := getPRNumber(args, client)
 	if err != nil {
@@ -81,6 +83,16 @@ func runApply(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	suggestions, alreadyApplied := splitAppliedSuggestions(suggestions)
+	if len(alreadyApplied) > 0 {
+		offerToResolveApplied(client, prNumber, alreadyApplied)
+		if len(suggestions) == 0 {
+			fmt.Println("All suggestions are already applied.")
+			return nil
+		}
+		fmt.Println()
+	}
+
 	if len(suggestions) == 0 {
 		if applyFile != "" {
 			fmt.Printf("No unresolved suggestions found for file: %s\n", applyFile)
@@ -98,6 +110,7 @@ func runApply(cmd *cobra.Command, args []string) error {
 	app := applier.New()
 	app.SetDebug(applyDebug)
 	app.SetGitHubClient(client) // Pass GitHub client for resolving threads
+	app.SetDeferResolve(applyDeferResolve)
 
 	// Setup AI provider if needed (for interactive or --ai-auto)
 	if applyAIAuto || (!applyAll) {
@@ -119,15 +132,116 @@ func runApply(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	if applyAIAuto {
-		return app.ApplyAllWithAI(suggestions)
+	switch {
+	case applyAIAuto:
+		err = app.ApplyAllWithAI(suggestions)
+	case applyAll:
+		err = app.ApplyAll(suggestions)
+	default:
+		err = app.ApplyInteractive(suggestions)
+	}
+	if err != nil || !applyDeferResolve {
+		return err
 	}
+	return deferResolve(client, prNumber, app.Addressed())
+}
 
-	if applyAll {
-		return app.ApplyAll(suggestions)
+// deferResolve queues the addressed threads, to be resolved by finish once the fix is pushed
+func deferResolve(client *github.Client, prNumber int, addressed []*github.ReviewComment) error {
+	if len(addressed) == 0 {
+		return nil
+	}
+
+	repo, err := client.GetRepo()
+	if err != nil {
+		return err
+	}
+	head, err := gitCommand("rev-parse", "HEAD")
+	if err != nil {
+		return err
+	}
+	path, err := deferred.QueuePath()
+	if err != nil {
+		return err
+	}
+	queue, err := deferred.Load(path)
+	if err != nil {
+		return err
 	}
 
-	return app.ApplyInteractive(suggestions)
+	for _, comment := range addressed {
+		queue.Add(deferred.Thread{
+			Host:        client.Host(),
+			Repo:        repo,
+			PullRequest: prNumber,
+			ThreadID:    comment.ThreadID,
+			CommentID:   comment.ID,
+			Path:        comment.Path,
+			Location:    comment.Location(),
+			BaseSHA:     strings.TrimSpace(head),
+		})
+	}
+	if err := queue.Save(); err != nil {
+		return fmt.Errorf("failed to save the threads to resolve: %w", err)
+	}
+
+	fmt.Printf("\n🕓 %d thread(s) will be resolved once the fix is pushed.\n", len(addressed))
+	fmt.Println("Commit and push the changes, then run: gh prreview finish")
+	fmt.Println("Or resolve them on every push with: gh prreview finish --install-hook")
+	return nil
+}
+
+// splitAppliedSuggestions separates the suggestions whose code is already in the local files
+func splitAppliedSuggestions(suggestions []*github.ReviewComment) (pending, applied []*github.ReviewComment) {
+	for _, suggestion := range suggestions {
+		ok, err := codecontext.SuggestionApplied(suggestion)
+		if err != nil && applyDebug {
+			fmt.Fprintf(os.Stderr, "[DEBUG] Could not check the suggestion of comment %d: %v\n", suggestion.ID, err)
+		}
+		if ok {
+			applied = append(applied, suggestion)
+		} else {
+			pending = append(pending, suggestion)
+		}
+	}
+	return pending, applied
+}
+
+// offerToResolveApplied lists the suggestions skipped as already applied and, in interactive
+// mode, offers to resolve their threads at once
+func offerToResolveApplied(client *github.Client, prNumber int, applied []*github.ReviewComment) {
+	fmt.Printf("Skipping %d suggestion(s) already applied:\n", len(applied))
+	var unresolved []*github.ReviewComment
+	for _, comment := range applied {
+		fmt.Printf("  • %s\n", ui.CreateHyperlink(comment.HTMLURL, comment.Location()))
+		if !comment.IsResolved() && comment.ThreadID != "" {
+			unresolved = append(unresolved, comment)
+		}
+	}
+	if len(unresolved) == 0 {
+		return
+	}
+	if applyAll || applyAIAuto {
+		fmt.Println(ui.Colorize(ui.ColorGray, "Resolve their threads with: gh prreview resolve "+pullRequestArg(client, prNumber)+" --applied"))
+		return
+	}
+
+	fmt.Printf("\n%s ", ui.Colorize(ui.ColorYellow, fmt.Sprintf("Resolve their %d thread(s)? [y/N]", len(unresolved))))
+	response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		return
+	}
+	for _, comment := range unresolved {
+		if err := client.ResolveThread(comment.ThreadID); err != nil {
+			fmt.Printf("❌ Failed to resolve the thread of %s: %v\n", comment.Location(), err)
+			continue
+		}
+		fmt.Printf("✅ Resolved the thread of %s\n", comment.Location())
+	}
 }
 
 // checkCleanWorkingDirectory checks if the git working directory is clean
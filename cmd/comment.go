@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	commentDebug      bool
+	commentBody       string
+	commentSide       string
+	commentPending    bool
+	commentEvent      string
+	commentReviewBody string
+)
+
+var commentCmd = &cobra.Command{
+	Use:   "comment [PR] PATH:LINE[-LINE]",
+	Short: "Leave an inline review comment",
+	Long: `Comment on a line, or a range of lines, of a pull request without opening the browser.
+The lines must be part of the pull request diff on the chosen side: RIGHT for the new code,
+LEFT for removed lines. The body is read from $EDITOR unless --body is given.
+
+By default the comment is posted right away as its own review. With --pending it is added to
+your pending review instead, so several comments can be batched; --event then submits the
+pending review, with the comment, as APPROVE, REQUEST_CHANGES or COMMENT.`,
+	Example: `  gh prreview comment main.go:42 --body "Could this be a constant?"
+  gh prreview comment 123 pkg/api.go:10-14 --pending
+  gh prreview comment pkg/api.go:30 --event request-changes --review-body "A few issues"`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runComment,
+}
+
+func init() {
+	commentCmd.Flags().BoolVar(&commentDebug, "debug", false, "Enable debug output")
+	commentCmd.Flags().StringVarP(&commentBody, "body", "b", "", "Comment body (opens $EDITOR when empty)")
+	commentCmd.Flags().StringVar(&commentSide, "side", "RIGHT", "Side of the diff: RIGHT (new code) or LEFT (removed code)")
+	commentCmd.Flags().BoolVar(&commentPending, "pending", false, "Add the comment to your pending review instead of posting it")
+	commentCmd.Flags().StringVarP(&commentEvent, "event", "e", "", "Submit the pending review with the comment: approve, request-changes or comment")
+	commentCmd.Flags().StringVar(&commentReviewBody, "review-body", "", "Summary comment of the submitted review")
+}
+
+func runComment(cmd *cobra.Command, args []string) error {
+	if commentPending && commentEvent != "" {
+		return fmt.Errorf("--pending cannot be combined with --event")
+	}
+	if commentReviewBody != "" && commentPending {
+		return fmt.Errorf("--review-body is set when submitting the review, not with --pending")
+	}
+
+	event := "COMMENT"
+	if commentEvent != "" {
+		var err error
+		if event, err = parseReviewEvent(commentEvent); err != nil {
+			return err
+		}
+	}
+
+	side := diffposition.DiffSide(strings.ToUpper(commentSide))
+	if side != diffposition.DiffSideRight && side != diffposition.DiffSideLeft {
+		return fmt.Errorf("invalid side %q (expected RIGHT or LEFT)", commentSide)
+	}
+
+	path, startLine, endLine, err := parseLineLocation(args[len(args)-1])
+	if err != nil {
+		return err
+	}
+
+	client := newClient(commentDebug)
+
+	prNumber, err := getPRNumber(args[:len(args)-1], client)
+	if err != nil {
+		return err
+	}
+
+	pr, err := client.GetPullRequest(prNumber)
+	if err != nil {
+		return err
+	}
+	files, err := client.FetchPullRequestFiles(prNumber)
+	if err != nil {
+		return err
+	}
+	if err := checkCommentable(files, path, side, startLine, endLine); err != nil {
+		return err
+	}
+
+	body := commentBody
+	if body == "" {
+		if body, err = ui.EditText(""); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(body) == "" {
+		return fmt.Errorf("comment body is required")
+	}
+
+	comment := github.DraftReviewComment{Path: path, Line: endLine, Side: string(side), Body: body}
+	if startLine != endLine {
+		comment.StartLine = startLine
+		comment.StartSide = string(side)
+	}
+	location := draftLocation(comment)
+
+	pending, err := client.PendingReview(prNumber)
+	if err != nil {
+		return err
+	}
+
+	prLink := ui.CreateHyperlink(pr.HTMLURL, ui.Colorize(ui.ColorCyan, fmt.Sprintf("PR #%d", prNumber)))
+
+	switch {
+	case commentPending && pending == nil:
+		if _, err := client.CreateReview(prNumber, pr.HeadSHA, "", "", []github.DraftReviewComment{comment}); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Started a pending review on %s with a comment on %s\n", prLink, location)
+	case commentPending:
+		if err := client.AddPendingReviewComment(pending.NodeID, comment); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Added a comment on %s to your pending review on %s\n", location, prLink)
+	case pending != nil && commentEvent == "":
+		return fmt.Errorf("you have a pending review on PR #%d; use --pending to add the comment to it, or --event to submit it with the comment", prNumber)
+	case pending != nil:
+		if err := client.AddPendingReviewComment(pending.NodeID, comment); err != nil {
+			return err
+		}
+		review, err := client.SubmitReview(prNumber, pending.ID, event, commentReviewBody)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Submitted your review on %s (%s), with a comment on %s\n", prLink, reviewStateLabel(review.State), location)
+	default:
+		review, err := client.CreateReview(prNumber, pr.HeadSHA, commentReviewBody, event, []github.DraftReviewComment{comment})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Commented on %s in %s (%s)\n", location, prLink, reviewStateLabel(review.State))
+	}
+	return nil
+}
+
+// parseLineLocation parses PATH:LINE or PATH:START-END
+func parseLineLocation(location string) (string, int, int, error) {
+	idx := strings.LastIndex(location, ":")
+	if idx <= 0 {
+		return "", 0, 0, fmt.Errorf("invalid location %q (expected PATH:LINE or PATH:START-END)", location)
+	}
+	path, lines := location[:idx], location[idx+1:]
+
+	startText, endText, isRange := strings.Cut(lines, "-")
+	start, err := strconv.Atoi(startText)
+	if err != nil || start <= 0 {
+		return "", 0, 0, fmt.Errorf("invalid line in %q", location)
+	}
+	end := start
+	if isRange {
+		end, err = strconv.Atoi(endText)
+		if err != nil || end < start {
+			return "", 0, 0, fmt.Errorf("invalid line range in %q", location)
+		}
+	}
+	return strings.TrimPrefix(path, "./"), start, end, nil
+}
+
+// checkCommentable makes sure GitHub accepts a comment on lines start to end of path, which
+// must fall in one range of the pull request diff on side
+func checkCommentable(files []*github.PullRequestFile, path string, side diffposition.DiffSide, start, end int) error {
+	var file *github.PullRequestFile
+	for _, f := range files {
+		if f.Path == path {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return fmt.Errorf("%s is not changed by the pull request", path)
+	}
+	if file.Patch == "" {
+		return fmt.Errorf("no diff is available for %s (binary or too large), its lines cannot be commented on", path)
+	}
+
+	ranges, err := diffposition.GetCommentingRanges(file.Patch, side == diffposition.DiffSideLeft)
+	if err != nil {
+		return fmt.Errorf("failed to parse the diff of %s: %w", path, err)
+	}
+	if diffposition.IsRangeCommentable(ranges, start, end) {
+		return nil
+	}
+
+	valid := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		if r[0] == r[1] {
+			valid = append(valid, strconv.Itoa(r[0]))
+		} else {
+			valid = append(valid, fmt.Sprintf("%d-%d", r[0], r[1]))
+		}
+	}
+	lines := strconv.Itoa(start)
+	if end != start {
+		lines = fmt.Sprintf("%d-%d", start, end)
+	}
+	if len(valid) == 0 {
+		return fmt.Errorf("no line of %s can be commented on the %s side", path, side)
+	}
+	return fmt.Errorf("line %s of %s is not part of the diff on the %s side; commentable lines: %s",
+		lines, path, side, strings.Join(valid, ", "))
+}
+
+// parseReviewEvent accepts approve, request-changes or comment, in any case and with
+// dashes or underscores, and returns the event name of the API
+func parseReviewEvent(event string) (string, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(event), "-", "_"))
+	switch normalized {
+	case "APPROVE", "REQUEST_CHANGES", "COMMENT":
+		return normalized, nil
+	default:
+		return "", fmt.Errorf("invalid review event %q (expected approve, request-changes or comment)", event)
+	}
+}
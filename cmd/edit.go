@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	editDebug   bool
+	editBody    string
+	deleteDebug bool
+	deleteYes   bool
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit COMMENT",
+	Short: "Edit one of your review comments",
+	Long: `Edit a review comment or reply you wrote, given its ID or URL. The current body opens in
+$EDITOR unless --body is given.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete COMMENT",
+	Short: "Delete one of your review comments",
+	Long:  `Delete a review comment or reply you wrote, given its ID or URL, after confirmation.`,
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelete,
+}
+
+func init() {
+	editCmd.Flags().BoolVar(&editDebug, "debug", false, "Enable debug output")
+	editCmd.Flags().StringVarP(&editBody, "body", "b", "", "New comment body (opens $EDITOR when empty)")
+	deleteCmd.Flags().BoolVar(&deleteDebug, "debug", false, "Enable debug output")
+	deleteCmd.Flags().BoolVarP(&deleteYes, "yes", "y", false, "Delete without asking for confirmation")
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	client := newClient(editDebug)
+
+	commentID, err := commentArg(args[0], client)
+	if err != nil {
+		return err
+	}
+	comment, err := ownComment(client, commentID)
+	if err != nil {
+		return err
+	}
+
+	body := editBody
+	if body == "" {
+		if body, err = ui.EditText(comment.Body); err != nil {
+			return err
+		}
+	}
+	if strings.TrimSpace(body) == strings.TrimSpace(comment.Body) {
+		fmt.Println("No changes, the comment was left as is.")
+		return nil
+	}
+
+	edited, err := client.EditReviewComment(commentID, body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Edited %s\n", ui.CreateHyperlink(edited.HTMLURL, fmt.Sprintf("comment %d", commentID)))
+	return nil
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	client := newClient(deleteDebug)
+
+	commentID, err := commentArg(args[0], client)
+	if err != nil {
+		return err
+	}
+	comment, err := ownComment(client, commentID)
+	if err != nil {
+		return err
+	}
+
+	if !deleteYes {
+		fmt.Printf("%s\n", ui.Colorize(ui.ColorCyan, fmt.Sprintf("Comment %d by @%s:", comment.ID, comment.Author)))
+		fmt.Printf("%s\n", ui.WrapText(comment.Body, 80))
+		fmt.Printf("\n%s ", ui.Colorize(ui.ColorYellow, "Delete this comment? [y/N]"))
+		response, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		response = strings.ToLower(strings.TrimSpace(response))
+		if response != "y" && response != "yes" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if err := client.DeleteReviewComment(commentID); err != nil {
+		return err
+	}
+	fmt.Printf("🗑️  Deleted comment %d\n", commentID)
+	return nil
+}
+
+// commentArg returns the review comment given as an ID or a URL, which also selects the
+// repository
+func commentArg(arg string, client *github.Client) (int64, error) {
+	if ref, err := github.ParsePRRef(arg); err == nil && ref.CommentID != 0 {
+		if _, err := client.ResolvePRRef(ref); err != nil {
+			return 0, err
+		}
+		return ref.CommentID, nil
+	}
+	return parseCommentID(arg)
+}
+
+// ownComment fetches a review comment, making sure we wrote it
+func ownComment(client *github.Client, commentID int64) (*github.ThreadComment, error) {
+	comment, err := client.GetReviewComment(commentID)
+	if err != nil {
+		return nil, err
+	}
+	login, err := client.CurrentUser()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(comment.Author, login) {
+		return nil, fmt.Errorf("comment %d was written by @%s; only your own comments can be changed", commentID, comment.Author)
+	}
+	return comment, nil
+}
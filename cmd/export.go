@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chmouel/gh-prreview/pkg/format"
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportFormat       string
+	exportOutput       string
+	exportUnresolved   bool
+	exportContextLines int
+	exportDebug        bool
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export [PR]",
+	Short: "Export review threads as a Markdown or HTML report",
+	Long: `Write a self-contained report of the review threads of a pull request, grouped by file,
+with the code they refer to, suggestions rendered as diffs, replies, status and links.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runExport,
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "md", "Report format: md or html")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "Write the report to a file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportUnresolved, "unresolved", false, "Only include unresolved threads")
+	exportCmd.Flags().IntVar(&exportContextLines, "context-lines", 3, "Number of surrounding lines shown with the code of each thread")
+	exportCmd.Flags().BoolVar(&exportDebug, "debug", false, "Enable debug output")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	var write func(io.Writer, *format.Report) error
+	switch exportFormat {
+	case "md", "markdown":
+		write = format.WriteMarkdown
+	case "html":
+		write = format.WriteHTML
+	default:
+		return fmt.Errorf("unknown format %q (expected md or html)", exportFormat)
+	}
+
+	client := newClient(exportDebug)
+
+	prNumber, err := getPRNumber(args, client)
+	if err != nil {
+		return err
+	}
+
+	repo, err := client.GetRepo()
+	if err != nil {
+		return err
+	}
+
+	comments, err := client.FetchReviewComments(prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch review comments: %w", err)
+	}
+
+	if exportUnresolved {
+		unresolved := make([]*github.ReviewComment, 0, len(comments))
+		for _, comment := range comments {
+			if !comment.IsResolved() {
+				unresolved = append(unresolved, comment)
+			}
+		}
+		comments = unresolved
+	}
+
+	report := format.NewReport(repo, prNumber, comments, exportContextLines)
+
+	if exportOutput == "" {
+		return write(os.Stdout, report)
+	}
+
+	file, err := os.Create(exportOutput)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", exportOutput, err)
+	}
+	if err := write(file, report); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d thread(s) to %s\n", report.Threads, exportOutput)
+	return nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/deferred"
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	finishDebug         bool
+	finishPush          bool
+	finishReply         bool
+	finishDryRun        bool
+	finishHook          bool
+	finishInstallHook   bool
+	finishUninstallHook bool
+)
+
+var finishCmd = &cobra.Command{
+	Use:   "finish [PR]",
+	Short: "Resolve the threads addressed with apply --defer-resolve once pushed",
+	Long: `Resolve the review threads queued by apply --defer-resolve whose fix has been pushed: the
+latest commit changing the commented file since the suggestion was applied must be part of
+the head of the pull request. Threads whose fix is not committed or pushed yet stay queued.
+
+With --push, git push runs first and nothing is resolved when it fails. --install-hook sets
+up a git hook running finish after every successful push instead.`,
+	Example: `  gh prreview finish --push --reply
+  gh prreview finish --dry-run
+  gh prreview finish --install-hook --reply`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runFinish,
+}
+
+func init() {
+	finishCmd.Flags().BoolVar(&finishDebug, "debug", false, "Enable debug output")
+	finishCmd.Flags().BoolVar(&finishPush, "push", false, "Run git push first")
+	finishCmd.Flags().BoolVar(&finishReply, "reply", false, `Reply "Fixed in <sha>" on each thread before resolving it`)
+	finishCmd.Flags().BoolVar(&finishDryRun, "dry-run", false, "List the threads that would be resolved without resolving them")
+	finishCmd.Flags().BoolVar(&finishInstallHook, "install-hook", false, "Install a git hook running finish after each push (keeps --reply)")
+	finishCmd.Flags().BoolVar(&finishUninstallHook, "uninstall-hook", false, "Remove the git hook installed by --install-hook")
+	finishCmd.Flags().BoolVar(&finishHook, "hook", false, "Run from the git hook: stay quiet when there is nothing to do and never fail")
+	_ = finishCmd.Flags().MarkHidden("hook")
+}
+
+func runFinish(cmd *cobra.Command, args []string) error {
+	if finishInstallHook || finishUninstallHook {
+		return manageFinishHook()
+	}
+
+	if finishPush {
+		push := exec.Command("git", "push")
+		push.Stdout = os.Stdout
+		push.Stderr = os.Stderr
+		if err := push.Run(); err != nil {
+			return fmt.Errorf("git push failed, no thread was resolved: %w", err)
+		}
+	}
+
+	err := finishDeferred(args)
+	if finishHook && err != nil {
+		// Never make the git operation that ran the hook look failed
+		fmt.Fprintf(os.Stderr, "gh prreview finish: %v\n", err)
+		return nil
+	}
+	return err
+}
+
+// manageFinishHook installs or removes the reference-transaction hook of the checkout
+func manageFinishHook() error {
+	path, err := deferred.HookPath()
+	if err != nil {
+		return err
+	}
+	if finishUninstallHook {
+		if err := deferred.UninstallHook(path); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Removed %s\n", path)
+		return nil
+	}
+
+	command := "gh prreview finish --hook"
+	if finishReply {
+		command += " --reply"
+	}
+	if err := deferred.InstallHook(path, command); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Installed %s\n", path)
+	fmt.Println("Threads queued with apply --defer-resolve are now resolved after each successful push.")
+	return nil
+}
+
+// finishDeferred resolves the queued threads whose fix is pushed
+func finishDeferred(args []string) error {
+	path, err := deferred.QueuePath()
+	if err != nil {
+		return err
+	}
+	queue, err := deferred.Load(path)
+	if err != nil {
+		return err
+	}
+	if len(queue.Threads) == 0 {
+		if !finishHook {
+			fmt.Println("No thread is waiting for a push.")
+		}
+		return nil
+	}
+
+	client := newClient(finishDebug)
+
+	// A PR argument limits finish to its threads
+	onlyRepo, onlyPR := "", 0
+	if len(args) > 0 {
+		if onlyPR, err = getPRNumber(args, client); err != nil {
+			return err
+		}
+		repo, err := client.GetRepo()
+		if err != nil {
+			return err
+		}
+		onlyRepo = client.Host() + "/" + repo
+	}
+
+	// A PR whose head cannot be fetched is skipped, its threads stay queued
+	heads := make(map[string]string)
+	failed := make(map[string]bool)
+	resolved, waiting := 0, 0
+	current := ""
+	for _, thread := range append([]deferred.Thread(nil), queue.Threads...) {
+		repo := thread.HostRepo()
+		if onlyPR != 0 && (!strings.EqualFold(repo, onlyRepo) || thread.PullRequest != onlyPR) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s#%d", repo, thread.PullRequest)
+		if failed[key] {
+			waiting++
+			continue
+		}
+		// Threads of several hosts can be queued: select the thread's own for every request
+		if repo != current {
+			client.SetRepo(repo)
+			current = repo
+		}
+		head, ok := heads[key]
+		if !ok {
+			pr, err := client.GetPullRequest(thread.PullRequest)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ %s: %v\n", key, err)
+				failed[key] = true
+				waiting++
+				continue
+			}
+			head = pr.HeadSHA
+			heads[key] = head
+		}
+
+		fix, err := deferred.FixCommit(thread)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ %s: %v\n", thread.Location, err)
+			waiting++
+			continue
+		}
+		if fix == "" || !deferred.IsPushed(fix, head) {
+			state := "not pushed yet"
+			if fix == "" {
+				state = "not committed yet"
+			}
+			if !finishHook {
+				fmt.Printf("🕓 %s: the fix is %s\n", thread.Location, state)
+			}
+			waiting++
+			continue
+		}
+
+		if finishDryRun {
+			fmt.Printf("Would resolve %s, fixed in %s\n", thread.Location, shortSHA(fix))
+			resolved++
+			continue
+		}
+		if err := resolveDeferred(client, thread, fix); err != nil {
+			fmt.Printf("❌ %s: %v\n", thread.Location, err)
+			waiting++
+			continue
+		}
+		queue.Remove(thread.ThreadID)
+		resolved++
+	}
+
+	if !finishDryRun {
+		if err := queue.Save(); err != nil {
+			return fmt.Errorf("failed to save the threads still to resolve: %w", err)
+		}
+	}
+
+	if finishHook && resolved == 0 {
+		return nil
+	}
+	verb := "Resolved"
+	if finishDryRun {
+		verb = "Dry run: would resolve"
+	}
+	fmt.Printf("%s %d thread(s), %d still waiting\n", verb, resolved, waiting)
+	return nil
+}
+
+// resolveDeferred resolves a thread whose fix is pushed, replying first with --reply
+func resolveDeferred(client *github.Client, thread deferred.Thread, fix string) error {
+	if finishReply {
+		if _, err := client.ReplyToComment(thread.PullRequest, thread.CommentID, fmt.Sprintf("Fixed in %s.", fix)); err != nil {
+			return err
+		}
+	}
+	if err := client.ResolveThread(thread.ThreadID); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s resolved, fixed in %s\n", ui.Colorize(ui.ColorGreen, "✓"), thread.Location, shortSHA(fix))
+	return nil
+}
@@ -1,11 +1,15 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"strconv"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/format"
 	"github.com/chmouel/gh-prreview/pkg/github"
 	"github.com/chmouel/gh-prreview/pkg/ui"
 	"github.com/spf13/cobra"
@@ -17,14 +21,23 @@ var (
 	listLLM          bool
 	listJSON         bool
 	listCodeContext  bool
+	listReviews      bool
+	listConversation bool
+	listLocalContext bool
+	listContextLines int
+	listFormat       string
+	listMaxTokens    int
 )
 
 var listCmd = &cobra.Command{
-	Use:   "list [PR_NUMBER] [THREAD_ID]",
+	Use:   "list [PR] [THREAD_ID]",
 	Short: "List review comments for a pull request",
-	Long:  `List all review comments and suggestions for a pull request.`,
-	Args:  cobra.MaximumNArgs(2),
-	RunE:  runList,
+	Long: `List all review comments and suggestions for a pull request.
+
+PR is a number, a PR URL, [HOST/]OWNER/REPO#NUMBER or a branch name. Given the URL of a
+review comment (ending in #discussion_r<ID>), only the thread of that comment is listed.`,
+	Args: cobra.MaximumNArgs(2),
+	RunE: runList,
 }
 
 func init() {
@@ -33,23 +46,52 @@ func init() {
 	listCmd.Flags().BoolVar(&listLLM, "llm", false, "Output in a format suitable for LLM consumption")
 	listCmd.Flags().BoolVar(&listJSON, "json", false, "Output raw review comment JSON (includes thread replies)")
 	listCmd.Flags().BoolVar(&listCodeContext, "code-context", false, "Display surrounding diff context for each comment")
+	listCmd.Flags().BoolVar(&listLocalContext, "local-context", false, "Display the current local code around each comment (falls back to the diff hunk)")
+	listCmd.Flags().IntVar(&listContextLines, "context-lines", 3, "Number of surrounding lines shown with --local-context")
+	listCmd.Flags().BoolVar(&listReviews, "reviews", false, "Include review summaries and group comments under their review")
+	listCmd.Flags().BoolVar(&listConversation, "conversation", false, "Include general PR conversation comments, shown chronologically with the review threads")
+	listCmd.Flags().IntVar(&listMaxTokens, "max-tokens", 0, "With --llm, limit the output to about this many tokens, keeping unresolved review comments first")
+	listCmd.Flags().StringVar(&listFormat, "format", "text", "Output format: text, json, ndjson, sarif, quickfix or vscode")
+}
+
+// reviewGroup is a review together with the inline comments submitted with it.
+// A nil Review holds the comments that could not be matched to a displayed review.
+type reviewGroup struct {
+	Review   *github.Review
+	Comments []*github.ReviewComment
+}
+
+// timelineEntry is one item of the PR conversation: a general PR comment, a review with its
+// inline comments, or a single inline thread. Exactly one of the pointers is set.
+type timelineEntry struct {
+	When         time.Time
+	IssueComment *github.IssueComment
+	Group        *reviewGroup
+	Comment      *github.ReviewComment
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	client := github.NewClient()
-	client.SetDebug(listDebug)
-	if repoFlag != "" {
-		client.SetRepo(repoFlag)
-	}
+	client := newClient(listDebug)
 
 	if listJSON && listLLM {
 		return fmt.Errorf("--json cannot be combined with --llm")
 	}
 
-	prNumber, err := getPRNumber(args, client)
+	switch listFormat {
+	case "text":
+	case "json", "ndjson", "sarif", "quickfix", "vscode":
+		if listJSON || listLLM {
+			return fmt.Errorf("--format %s cannot be combined with --json or --llm", listFormat)
+		}
+	default:
+		return fmt.Errorf("unknown format %q (expected text, json, ndjson, sarif, quickfix or vscode)", listFormat)
+	}
+
+	ref, err := getPRRef(args, client)
 	if err != nil {
 		return err
 	}
+	prNumber := ref.Number
 
 	var threadID string
 	if len(args) > 1 {
@@ -61,10 +103,23 @@ func runList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to fetch review comments: %w", err)
 	}
 
-	// Filter out resolved comments unless --all is specified
+	// A comment URL selects its thread, even when it is resolved
+	if ref.CommentID != 0 && threadID == "" {
+		comment := findCommentThread(comments, ref.CommentID)
+		if comment == nil {
+			return fmt.Errorf("review comment %d not found in PR #%d", ref.CommentID, prNumber)
+		}
+		if comment.ThreadID == "" {
+			// Thread IDs are missing when the review threads could not be fetched
+			comments = []*github.ReviewComment{comment}
+		}
+		threadID = comment.ThreadID
+	}
+
+	// Filter out resolved comments unless --all is specified or a thread was asked for
 	filteredComments := make([]*github.ReviewComment, 0)
 	for _, comment := range comments {
-		if listShowResolved || !comment.IsResolved() {
+		if listShowResolved || ref.CommentID != 0 || !comment.IsResolved() {
 			filteredComments = append(filteredComments, comment)
 		}
 	}
@@ -73,16 +128,49 @@ func runList(cmd *cobra.Command, args []string) error {
 		filteredComments = filterByThreadID(filteredComments, threadID)
 	}
 
+	var groups []reviewGroup
+	if listReviews {
+		reviews, err := client.FetchReviews(prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to fetch reviews: %w", err)
+		}
+		groups = groupCommentsByReview(reviews, filteredComments, threadID == "")
+	}
+
+	var issueComments []*github.IssueComment
+	if listConversation && threadID == "" {
+		issueComments, err = client.FetchIssueComments(prNumber)
+		if err != nil {
+			return fmt.Errorf("failed to fetch PR comments: %w", err)
+		}
+	}
+
+	useTimeline := listReviews || listConversation
+
+	if listFormat != "text" {
+		if threadID != "" && len(filteredComments) == 0 {
+			return fmt.Errorf("no review comments found for thread ID %s", threadID)
+		}
+		return writeFormattedList(client, prNumber, groups, issueComments, filteredComments)
+	}
+
 	if listJSON {
-		if len(filteredComments) == 0 {
+		if len(filteredComments) == 0 && len(groups) == 0 && len(issueComments) == 0 {
 			if threadID != "" {
 				return fmt.Errorf("no review comments found for thread ID %s", threadID)
 			}
-			fmt.Println("[]")
-			return nil
+			if !useTimeline {
+				fmt.Println("[]")
+				return nil
+			}
 		}
 
-		jsonOutput, err := dumpCommentsJSON(client, prNumber, filteredComments)
+		var jsonOutput string
+		if useTimeline {
+			jsonOutput, err = dumpConversationJSON(client, prNumber, groups, issueComments, filteredComments)
+		} else {
+			jsonOutput, err = dumpCommentsJSON(client, prNumber, filteredComments)
+		}
 		if err != nil {
 			return err
 		}
@@ -90,7 +178,7 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	if len(filteredComments) == 0 {
+	if len(filteredComments) == 0 && len(groups) == 0 && len(issueComments) == 0 {
 		if threadID != "" {
 			fmt.Printf("No review comments found for thread ID %s.\n", threadID)
 			return nil
@@ -103,38 +191,140 @@ func runList(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	var timeline []timelineEntry
+	if useTimeline {
+		timeline = buildTimeline(groups, issueComments, filteredComments, listReviews)
+	}
+
 	// Use readable format if requested
 	if listLLM {
-		displayLLMFormat(filteredComments)
+		llmOutput := format.RenderLLM(filteredComments, format.LLMOptions{
+			DiffHunk:     listCodeContext,
+			CurrentCode:  listLocalContext,
+			ContextLines: listContextLines,
+			MaxTokens:    listMaxTokens,
+		})
+		if useTimeline {
+			displayLLMTimeline(timeline, llmOutput)
+		} else {
+			displayLLMFormat(filteredComments, llmOutput)
+		}
+		if llmOutput.Omitted > 0 || llmOutput.Shortened > 0 {
+			fmt.Print(format.LLMSeparator)
+			fmt.Printf("BUDGET: %d item(s) omitted and %d comment(s) shortened to fit --max-tokens %d\n", llmOutput.Omitted, llmOutput.Shortened, listMaxTokens)
+		}
 		return nil
 	}
 
-	fmt.Printf("Found %d review comment(s):\n", len(filteredComments))
-
-	for i, comment := range filteredComments {
-		displayComment(i+1, len(filteredComments), comment)
+	if len(issueComments) > 0 {
+		fmt.Printf("Found %d review comment(s) and %d PR comment(s):\n", len(filteredComments), len(issueComments))
+	} else {
+		fmt.Printf("Found %d review comment(s):\n", len(filteredComments))
 	}
 
+	displayCommentList(client, prNumber, timeline, filteredComments)
 	return nil
 }
 
+// displayCommentList displays the review comments, within the PR conversation when there is
+// a timeline, followed by the hint to resolve the suggestions already applied
+func displayCommentList(client *github.Client, prNumber int, timeline []timelineEntry, comments []*github.ReviewComment) {
+	// Checking a suggestion reads its local file, so it is done once per comment
+	applied := make(map[int64]bool)
+	for _, comment := range comments {
+		if suggestionApplied(comment) {
+			applied[comment.ID] = true
+		}
+	}
+
+	if len(timeline) > 0 {
+		index := 0
+		for _, entry := range timeline {
+			switch {
+			case entry.IssueComment != nil:
+				displayIssueComment(entry.IssueComment)
+			case entry.Group != nil:
+				displayReview(*entry.Group)
+				for _, comment := range entry.Group.Comments {
+					index++
+					displayComment(index, len(comments), comment, applied[comment.ID])
+				}
+			case entry.Comment != nil:
+				index++
+				displayComment(index, len(comments), entry.Comment, applied[entry.Comment.ID])
+			}
+		}
+	} else {
+		for i, comment := range comments {
+			displayComment(i+1, len(comments), comment, applied[comment.ID])
+		}
+	}
+
+	displayAppliedHint(client, prNumber, len(applied))
+}
+
 func getPRNumber(args []string, client *github.Client) (int, error) {
+	ref, err := getPRRef(args, client)
+	if err != nil {
+		return 0, err
+	}
+	return ref.Number, nil
+}
+
+// getPRRef resolves the PR given as first argument, or the PR of the current branch. A
+// reference naming a repository switches the client to it.
+func getPRRef(args []string, client *github.Client) (github.PRRef, error) {
 	if len(args) > 0 {
-		prNumber, err := strconv.Atoi(args[0])
+		ref, err := github.ParsePRRef(args[0])
+		if err != nil {
+			return github.PRRef{}, err
+		}
+		if ref.Repo != "" && repoFlag != "" && !sameRepo(repoFlag, ref.QualifiedRepo()) {
+			return github.PRRef{}, fmt.Errorf("%s is not in the repository given with --repo (%s)", args[0], repoFlag)
+		}
+		ref.Number, err = client.ResolvePRRef(ref)
 		if err != nil {
-			return 0, fmt.Errorf("invalid PR number: %s", args[0])
+			return github.PRRef{}, err
+		}
+		if ref.Branch != "" {
+			fmt.Fprintf(os.Stderr, "Found PR #%d for branch %s\n", ref.Number, ref.Branch)
 		}
-		return prNumber, nil
+		return ref, nil
 	}
 
 	// Get PR number for current branch
 	prNumber, err := client.GetCurrentBranchPR()
 	if err != nil {
-		return 0, err
+		return github.PRRef{}, err
 	}
 
 	fmt.Fprintf(os.Stderr, "Auto-detected PR #%d for current branch\n", prNumber)
-	return prNumber, nil
+	return github.PRRef{Number: prNumber}, nil
+}
+
+// sameRepo compares two [HOST/]OWNER/REPO, a missing host matching any
+func sameRepo(a, b string) bool {
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if strings.Count(a, "/") != strings.Count(b, "/") {
+		return strings.HasSuffix(a, "/"+b) || strings.HasSuffix(b, "/"+a)
+	}
+	return a == b
+}
+
+// findCommentThread returns the thread holding the review comment id, either as its first
+// comment or as a reply
+func findCommentThread(comments []*github.ReviewComment, id int64) *github.ReviewComment {
+	for _, comment := range comments {
+		if comment.ID == id {
+			return comment
+		}
+		for _, reply := range comment.ThreadComments {
+			if reply.ID == id {
+				return comment
+			}
+		}
+	}
+	return nil
 }
 
 func filterByThreadID(comments []*github.ReviewComment, threadID string) []*github.ReviewComment {
@@ -152,6 +342,236 @@ func dumpCommentsJSON(client *github.Client, prNumber int, comments []*github.Re
 	return client.DumpCommentsJSON(prNumber, commentIDs)
 }
 
+// writeFormattedList writes the comments in the structured --format output. Reviews and
+// issue comments are only part of the document when --reviews or --conversation asked for them.
+func writeFormattedList(client *github.Client, prNumber int, groups []reviewGroup, issueComments []*github.IssueComment, comments []*github.ReviewComment) error {
+	repo, err := client.GetRepo()
+	if err != nil {
+		return err
+	}
+
+	var reviews []*github.Review
+	if listReviews {
+		reviews = make([]*github.Review, 0, len(groups))
+		for _, group := range groups {
+			if group.Review != nil {
+				reviews = append(reviews, group.Review)
+			}
+		}
+	}
+
+	doc := format.NewDocument(repo, prNumber, comments, reviews, issueComments)
+	doc.RepositoryURL, _ = client.RepoURL()
+	switch listFormat {
+	case "ndjson":
+		return format.WriteNDJSON(os.Stdout, doc)
+	case "sarif":
+		return format.WriteSARIF(os.Stdout, doc)
+	case "quickfix":
+		return format.WriteQuickfix(os.Stdout, doc)
+	case "vscode":
+		return format.WriteProblems(os.Stdout, doc)
+	default:
+		return format.WriteJSON(os.Stdout, doc)
+	}
+}
+
+// dumpConversationJSON returns the raw reviews, PR conversation comments and review comments
+// as a single JSON object. The reviews and issue_comments keys are only present when the
+// corresponding data was requested.
+func dumpConversationJSON(client *github.Client, prNumber int, groups []reviewGroup, issueComments []*github.IssueComment, comments []*github.ReviewComment) (string, error) {
+	var output struct {
+		Reviews       json.RawMessage `json:"reviews,omitempty"`
+		IssueComments json.RawMessage `json:"issue_comments,omitempty"`
+		Comments      json.RawMessage `json:"comments"`
+	}
+
+	if listReviews {
+		reviewIDs := make([]int64, 0, len(groups))
+		for _, group := range groups {
+			if group.Review != nil {
+				reviewIDs = append(reviewIDs, group.Review.ID)
+			}
+		}
+
+		output.Reviews = json.RawMessage("[]")
+		if len(reviewIDs) > 0 {
+			reviewsJSON, err := client.DumpReviewsJSON(prNumber, reviewIDs)
+			if err != nil {
+				return "", err
+			}
+			output.Reviews = json.RawMessage(reviewsJSON)
+		}
+	}
+
+	if listConversation {
+		output.IssueComments = json.RawMessage("[]")
+		if len(issueComments) > 0 {
+			ids := make([]int64, 0, len(issueComments))
+			for _, comment := range issueComments {
+				ids = append(ids, comment.ID)
+			}
+			issueJSON, err := client.DumpIssueCommentsJSON(prNumber, ids)
+			if err != nil {
+				return "", err
+			}
+			output.IssueComments = json.RawMessage(issueJSON)
+		}
+	}
+
+	output.Comments = json.RawMessage("[]")
+	if len(comments) > 0 {
+		commentsJSON, err := dumpCommentsJSON(client, prNumber, comments)
+		if err != nil {
+			return "", err
+		}
+		output.Comments = json.RawMessage(commentsJSON)
+	}
+
+	encoded, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode JSON output: %w", err)
+	}
+	return string(encoded), nil
+}
+
+// buildTimeline merges PR conversation comments with either the review groups (when grouping
+// by review) or the individual review threads, ordered chronologically.
+func buildTimeline(groups []reviewGroup, issueComments []*github.IssueComment, comments []*github.ReviewComment, byReview bool) []timelineEntry {
+	entries := make([]timelineEntry, 0, len(groups)+len(issueComments)+len(comments))
+
+	if byReview {
+		for i := range groups {
+			group := &groups[i]
+			// Pending reviews are not submitted yet and go by their first comment
+			var when time.Time
+			if group.Review != nil && !group.Review.SubmittedAt.IsZero() {
+				when = group.Review.SubmittedAt
+			} else if len(group.Comments) > 0 {
+				when = group.Comments[0].CreatedAt
+			}
+			entries = append(entries, timelineEntry{When: when, Group: group})
+		}
+	} else {
+		for _, comment := range comments {
+			entries = append(entries, timelineEntry{When: comment.CreatedAt, Comment: comment})
+		}
+	}
+
+	for _, comment := range issueComments {
+		entries = append(entries, timelineEntry{When: comment.CreatedAt, IssueComment: comment})
+	}
+
+	// Entries without a time, like a pending review without comments, come last
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].When.IsZero() || entries[j].When.IsZero() {
+			return !entries[i].When.IsZero() && entries[j].When.IsZero()
+		}
+		return entries[i].When.Before(entries[j].When)
+	})
+
+	return entries
+}
+
+// groupCommentsByReview attaches each comment to the review it was submitted with. Reviews
+// without a summary body, a decisive state or any displayed comment are dropped, as are all
+// comment-less reviews when keepEmpty is false. Comments whose review is unknown are gathered
+// in a trailing group with a nil Review.
+func groupCommentsByReview(reviews []*github.Review, comments []*github.ReviewComment, keepEmpty bool) []reviewGroup {
+	byReview := make(map[int64][]*github.ReviewComment)
+	for _, comment := range comments {
+		byReview[comment.ReviewID] = append(byReview[comment.ReviewID], comment)
+	}
+
+	groups := make([]reviewGroup, 0, len(reviews))
+	for _, review := range reviews {
+		reviewComments := byReview[review.ID]
+		delete(byReview, review.ID)
+
+		if len(reviewComments) == 0 {
+			if !keepEmpty {
+				continue
+			}
+			if strings.TrimSpace(review.Body) == "" && review.State == "COMMENTED" {
+				continue
+			}
+		}
+		groups = append(groups, reviewGroup{Review: review, Comments: reviewComments})
+	}
+
+	var orphans []*github.ReviewComment
+	for _, comment := range comments {
+		if _, ok := byReview[comment.ReviewID]; ok {
+			orphans = append(orphans, comment)
+		}
+	}
+	if len(orphans) > 0 {
+		groups = append(groups, reviewGroup{Comments: orphans})
+	}
+
+	return groups
+}
+
+// reviewStateLabel returns a human readable, colorized label for a review state
+func reviewStateLabel(state string) string {
+	switch state {
+	case "APPROVED":
+		return ui.Colorize(ui.ColorGreen, "✅ Approved")
+	case "CHANGES_REQUESTED":
+		return ui.Colorize(ui.ColorRed, "❌ Changes requested")
+	case "DISMISSED":
+		return ui.Colorize(ui.ColorGray, "Dismissed")
+	case "PENDING":
+		return ui.Colorize(ui.ColorYellow, "Pending")
+	default:
+		return ui.Colorize(ui.ColorGray, "💬 Commented")
+	}
+}
+
+// displayReview displays a review header and its summary body
+func displayReview(group reviewGroup) {
+	if group.Review == nil {
+		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, "Other review comments"))
+		fmt.Printf("%s\n", ui.Colorize(ui.ColorGray, "════════════════════════════════════════"))
+		return
+	}
+
+	review := group.Review
+	title := ui.CreateHyperlink(review.HTMLURL, fmt.Sprintf("Review by @%s", review.Author))
+	fmt.Printf("\n%s %s %s\n",
+		ui.Colorize(ui.ColorCyan, title),
+		reviewStateLabel(review.State),
+		ui.Colorize(ui.ColorGray, fmt.Sprintf("(ID %d, %d comment(s))", review.ID, len(group.Comments))))
+	fmt.Printf("%s\n", ui.Colorize(ui.ColorGray, "════════════════════════════════════════"))
+
+	if body := strings.TrimSpace(review.Body); body != "" {
+		rendered, err := ui.RenderMarkdown(body)
+		if err == nil && rendered != "" {
+			fmt.Println(rendered)
+		} else {
+			fmt.Printf("%s\n", ui.WrapText(body, 80))
+		}
+	}
+}
+
+// displayIssueComment displays a general PR conversation comment
+func displayIssueComment(comment *github.IssueComment) {
+	title := ui.CreateHyperlink(comment.HTMLURL, fmt.Sprintf("PR comment by @%s", comment.Author))
+	fmt.Printf("\n%s %s\n",
+		ui.Colorize(ui.ColorCyan, "💬 "+title),
+		ui.Colorize(ui.ColorGray, fmt.Sprintf("(ID %d, %s)", comment.ID, comment.CreatedAt.Local().Format("2006-01-02 15:04"))))
+	fmt.Printf("%s\n", ui.Colorize(ui.ColorGray, "────────────────────────────────────────"))
+
+	if body := strings.TrimSpace(comment.Body); body != "" {
+		rendered, err := ui.RenderMarkdown(body)
+		if err == nil && rendered != "" {
+			fmt.Println(rendered)
+		} else {
+			fmt.Printf("%s\n", ui.WrapText(body, 80))
+		}
+	}
+}
+
 func collectCommentIDs(comments []*github.ReviewComment) []int64 {
 	seen := make(map[int64]struct{})
 	ids := make([]int64, 0)
@@ -172,21 +592,25 @@ func collectCommentIDs(comments []*github.ReviewComment) []int64 {
 	return ids
 }
 
-// displayComment displays a single review comment with formatting
-func displayComment(index, total int, comment *github.ReviewComment) {
+// displayComment displays a single review comment with formatting, marking a suggestion
+// already applied to the local file
+func displayComment(index, total int, comment *github.ReviewComment, applied bool) {
 	// Create clickable link to the review comment
-	fileLocation := fmt.Sprintf("%s:%d", comment.Path, comment.Line)
-	clickableLocation := ui.CreateHyperlink(comment.HTMLURL, fileLocation)
+	clickableLocation := ui.CreateHyperlink(comment.HTMLURL, comment.Location())
 
 	// Header
-	fmt.Printf("\n%s\n",
-		ui.Colorize(ui.ColorCyan, fmt.Sprintf("[%d/%d] %s by @%s (ID %d)",
-			index, total, clickableLocation, comment.Author, comment.ID)))
+	header := fmt.Sprintf("[%d/%d] %s by @%s (ID %d)", index, total, clickableLocation, comment.Author, comment.ID)
+	if comment.IsFileLevel() {
+		header += " 📄 File comment"
+	}
+	fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, header))
 	fmt.Printf("%s\n", ui.Colorize(ui.ColorGray, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
 
 	// Show resolved status
 	if comment.IsResolved() {
 		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorGreen, "✅ Resolved"))
+	} else if applied {
+		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorGreen, "✔️  Already applied (the suggested code is in your local file)"))
 	}
 
 	// Show the review comment (without the suggestion block)
@@ -202,17 +626,28 @@ func displayComment(index, total int, comment *github.ReviewComment) {
 			fmt.Printf("%s\n", wrappedComment)
 		}
 	}
+	if reactions := comment.Reactions.String(); reactions != "" {
+		fmt.Printf("%s\n", reactions)
+	}
+
+	// Show the current local code around the comment, or at least the whole
+	// range of code a multi-line comment refers to
+	if listLocalContext && !comment.IsFileLevel() {
+		displayCommentedLines(comment, listContextLines)
+	} else if comment.IsMultiLine() {
+		displayCommentedLines(comment, 0)
+	}
 
 	// Show the suggestion if present
 	if comment.HasSuggestion {
 		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorYellow, "Suggested change:"))
-		fmt.Println(ui.ColorizeCode(comment.SuggestedCode))
+		fmt.Println(ui.ColorizeCode(comment.SuggestedCode, comment.Path))
 	}
 
 	// Show context (diff hunk) if available and requested
 	if listCodeContext && comment.DiffHunk != "" {
 		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorYellow, "Context:"))
-		fmt.Println(ui.ColorizeDiff(comment.DiffHunk))
+		fmt.Println(ui.ColorizeDiff(comment.DiffHunk, comment.Path))
 	}
 
 	// Show thread comments (replies)
@@ -235,47 +670,158 @@ func displayComment(index, total int, comment *github.ReviewComment) {
 					fmt.Printf("     %s\n", line)
 				}
 			}
+			if reactions := threadComment.Reactions.String(); reactions != "" {
+				fmt.Printf("     %s\n", reactions)
+			}
 		}
 	}
 
 	fmt.Println()
 }
 
-// displayLLMFormat displays review comments in a readable format for LLM consumption
-func displayLLMFormat(comments []*github.ReviewComment) {
-	for i, comment := range comments {
-		if i > 0 {
-			fmt.Println("---")
+// suggestionApplied reports whether an unresolved comment suggests code already in the local
+// file
+func suggestionApplied(comment *github.ReviewComment) bool {
+	if comment.IsResolved() || !comment.HasSuggestion {
+		return false
+	}
+	applied, err := codecontext.SuggestionApplied(comment)
+	if err != nil && listDebug {
+		fmt.Fprintf(os.Stderr, "[DEBUG] Could not check the suggestion of comment %d: %v\n", comment.ID, err)
+	}
+	return applied
+}
+
+// displayAppliedHint points to the bulk resolve of the threads whose suggestion is already
+// applied
+func displayAppliedHint(client *github.Client, prNumber, applied int) {
+	if applied == 0 {
+		return
+	}
+	fmt.Printf("%s\n", ui.Colorize(ui.ColorGray,
+		fmt.Sprintf("%d suggestion(s) already applied; resolve their threads with: gh prreview resolve %s --applied",
+			applied, pullRequestArg(client, prNumber))))
+}
+
+// displayCommentedLines shows the code a comment refers to with up to context surrounding
+// lines, read from the local file when possible and from the diff hunk otherwise
+func displayCommentedLines(comment *github.ReviewComment, context int) {
+	snippet, err := codecontext.ForComment(comment, context)
+	if err != nil {
+		if listDebug {
+			fmt.Fprintf(os.Stderr, "[DEBUG] Could not load code for comment %d: %v\n", comment.ID, err)
 		}
+		return
+	}
 
-		fmt.Printf("FILE: %s:%d\n", comment.Path, comment.Line)
-		fmt.Printf("COMMENT_ID: %d\n", comment.ID)
-		fmt.Printf("AUTHOR: %s\n", comment.Author)
-		fmt.Printf("URL: %s\n", comment.HTMLURL)
+	lineRange := fmt.Sprintf("line %d", snippet.Start)
+	if snippet.End > snippet.Start {
+		lineRange = fmt.Sprintf("lines %d-%d", snippet.Start, snippet.End)
+	}
 
-		if comment.IsResolved() {
-			fmt.Println("STATUS: resolved")
-		} else {
-			fmt.Println("STATUS: unresolved")
+	var title string
+	switch {
+	case snippet.FromHunk:
+		title = fmt.Sprintf("Commented code, %s (from diff hunk, local file not available):", lineRange)
+	case snippet.Relocated:
+		title = fmt.Sprintf("Current code, %s (moved from line %d):", lineRange, comment.StartLine)
+	default:
+		title = fmt.Sprintf("Current code, %s:", lineRange)
+	}
+	fmt.Printf("\n%s\n", ui.Colorize(ui.ColorYellow, title))
+	first, lines := snippet.Code()
+	fmt.Println(ui.FormatCodeLines(first, lines, snippet.Start, snippet.End, comment.Path))
+}
+
+// displayLLMFormat displays review comments in a readable format for LLM consumption,
+// skipping the ones that did not fit the token budget
+func displayLLMFormat(comments []*github.ReviewComment, output *format.LLMOutput) {
+	printed := 0
+	for _, comment := range comments {
+		block, ok := output.Block(comment)
+		if !ok {
+			continue
+		}
+		if printed > 0 {
+			fmt.Print(format.LLMSeparator)
 		}
+		fmt.Print(block)
+		printed++
+	}
+}
 
-		// Show the review comment (without suggestion block)
-		commentText := ui.StripSuggestionBlock(comment.Body)
-		if commentText != "" {
-			fmt.Printf("COMMENT:\n%s\n", commentText)
+// displayLLMTimeline displays the PR conversation for LLM consumption. Reviews and PR
+// comments are charged to what the review comments left of the token budget, in order.
+func displayLLMTimeline(timeline []timelineEntry, output *format.LLMOutput) {
+	printed := 0
+	for _, entry := range timeline {
+		var text string
+		switch {
+		case entry.IssueComment != nil:
+			if text = llmIssueComment(entry.IssueComment); !output.Charge(text) {
+				text = ""
+			}
+		case entry.Group != nil:
+			text = llmReview(*entry.Group, output)
+		case entry.Comment != nil:
+			text, _ = output.Block(entry.Comment)
+		}
+		if text == "" {
+			continue
 		}
+		if printed > 0 {
+			fmt.Print(format.LLMSeparator)
+		}
+		fmt.Print(text)
+		printed++
+	}
+}
+
+// llmIssueComment renders a PR conversation comment for LLM consumption
+func llmIssueComment(comment *github.IssueComment) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "PR_COMMENT_ID: %d\n", comment.ID)
+	fmt.Fprintf(&b, "AUTHOR: %s\n", comment.Author)
+	fmt.Fprintf(&b, "URL: %s\n", comment.HTMLURL)
+	fmt.Fprintf(&b, "CREATED_AT: %s\n", comment.CreatedAt.Format(time.RFC3339))
+	if body := strings.TrimSpace(comment.Body); body != "" {
+		fmt.Fprintf(&b, "COMMENT:\n%s\n", body)
+	}
+	return b.String()
+}
 
-		// Show the suggestion if present
-		if comment.HasSuggestion {
-			fmt.Printf("SUGGESTION:\n%s\n", comment.SuggestedCode)
+// llmReview renders a review and those of its comments that fit the token budget for LLM
+// consumption. A review whose comments were all dropped is left out unless it has a body;
+// when its header does not fit, the comments are rendered on their own.
+func llmReview(group reviewGroup, output *format.LLMOutput) string {
+	var blocks []string
+	for _, comment := range group.Comments {
+		if block, ok := output.Block(comment); ok {
+			blocks = append(blocks, block)
 		}
+	}
 
-		// Show thread replies
-		if len(comment.ThreadComments) > 0 {
-			fmt.Println("REPLIES:")
-			for j, reply := range comment.ThreadComments {
-				fmt.Printf("  [%d] %s: %s\n", j+1, reply.Author, reply.Body)
-			}
+	var b strings.Builder
+	if review := group.Review; review != nil {
+		fmt.Fprintf(&b, "REVIEW_ID: %d\n", review.ID)
+		fmt.Fprintf(&b, "REVIEW_AUTHOR: %s\n", review.Author)
+		fmt.Fprintf(&b, "REVIEW_STATE: %s\n", review.State)
+		fmt.Fprintf(&b, "REVIEW_URL: %s\n", review.HTMLURL)
+		if body := strings.TrimSpace(review.Body); body != "" {
+			fmt.Fprintf(&b, "REVIEW_BODY:\n%s\n", body)
+		} else if len(blocks) == 0 && len(group.Comments) > 0 {
+			return ""
+		}
+	} else {
+		if len(blocks) == 0 {
+			return ""
 		}
+		b.WriteString("REVIEW_ID: none\n")
+	}
+	fmt.Fprintf(&b, "REVIEW_COMMENTS: %d\n", len(blocks))
+
+	if header := b.String(); output.Charge(header) {
+		blocks = append([]string{header}, blocks...)
 	}
+	return strings.Join(blocks, format.LLMSeparator)
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// captureStdout returns what fn prints
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestDisplayCommentListApplied(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	content := "package main\n\nfunc main() {\n\tprintln(\"bye\")\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hunk := "@@ -1,3 +1,5 @@\n package main\n \n+func main() {\n+\tprintln(\"hi\")\n+}"
+	comment := func(id int64, suggestion string) *github.ReviewComment {
+		return &github.ReviewComment{
+			ID: id, Path: path, Author: "alice", Body: "```suggestion\n" + suggestion + "```",
+			Line: 4, StartLine: 4, EndLine: 4, OriginalStartLine: 4, OriginalEndLine: 4,
+			DiffHunk: hunk, DiffSide: diffposition.DiffSideRight,
+			HasSuggestion: true, SuggestedCode: suggestion,
+		}
+	}
+	comments := []*github.ReviewComment{
+		comment(1, "\tprintln(\"bye\")\n"),
+		comment(2, "\tprintln(\"hello\")\n"),
+	}
+
+	client := github.NewClient()
+	client.SetRepo("github.com/owner/repo")
+	out := captureStdout(t, func() {
+		displayCommentList(client, 42, nil, comments)
+	})
+
+	parts := strings.Split(out, "[2/2]")
+	if len(parts) != 2 {
+		t.Fatalf("expected both comments in the output:\n%s", out)
+	}
+	if !strings.Contains(parts[0], "Already applied") {
+		t.Errorf("the applied suggestion should be marked:\n%s", parts[0])
+	}
+	if strings.Contains(parts[1], "Already applied") {
+		t.Errorf("the pending suggestion should not be marked:\n%s", parts[1])
+	}
+	if !strings.Contains(out, "1 suggestion(s) already applied") || !strings.Contains(out, "https://github.com/owner/repo/pull/42 --applied") {
+		t.Errorf("expected the hint to resolve the applied suggestion:\n%s", out)
+	}
+}
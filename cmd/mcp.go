@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/applier"
+	"github.com/chmouel/gh-prreview/pkg/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpAllow []string
+	mcpDebug bool
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "Run a Model Context Protocol server over stdio",
+	Long: fmt.Sprintf(`Run a Model Context Protocol (MCP) server on stdin/stdout so coding agents can read
+the review comments of a pull request and act on them.
+
+The list-comments and get-thread tools are always available. The write tools
+(%s) are only exposed when allowed with --allow.`, strings.Join(mcp.WriteTools(), ", ")),
+	Args: cobra.NoArgs,
+	RunE: runMCP,
+}
+
+func init() {
+	mcpCmd.Flags().StringSliceVar(&mcpAllow, "allow", nil,
+		fmt.Sprintf("Write tools the agent may call (%s), or \"all\"", strings.Join(mcp.WriteTools(), ", ")))
+	mcpCmd.Flags().BoolVar(&mcpDebug, "debug", false, "Enable debug output (printed to stderr)")
+}
+
+func runMCP(cmd *cobra.Command, args []string) error {
+	client := newClient(mcpDebug)
+
+	a := applier.New()
+	a.SetDebug(mcpDebug)
+	a.SetGitHubClient(client)
+
+	allowed := mcpAllow
+	for _, name := range mcpAllow {
+		if name == "all" {
+			allowed = mcp.WriteTools()
+			break
+		}
+	}
+
+	server, err := mcp.NewServer(mcp.NewClientBackend(client, a), allowed)
+	if err != nil {
+		return err
+	}
+
+	return server.Serve(cmd.Context(), os.Stdin, os.Stdout)
+}
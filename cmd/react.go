@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reactDebug  bool
+	reactRemove bool
+)
+
+var reactCmd = &cobra.Command{
+	Use:   "react COMMENT REACTION",
+	Short: "React to a review comment",
+	Long: `Add an emoji reaction to a review comment or reply, given its ID or URL, e.g. to acknowledge
+feedback without adding a reply. REACTION is one of +1, -1, laugh, hooray, confused, heart,
+rocket or eyes, an alias such as thumbsup or tada, or the emoji itself. With --remove, your
+reaction is taken back.`,
+	Example: `  gh prreview react 123456 +1
+  gh prreview react https://github.com/owner/repo/pull/42#discussion_r123456 eyes
+  gh prreview react 123456 eyes --remove`,
+	Args: cobra.ExactArgs(2),
+	RunE: runReact,
+}
+
+func init() {
+	reactCmd.Flags().BoolVar(&reactDebug, "debug", false, "Enable debug output")
+	reactCmd.Flags().BoolVar(&reactRemove, "remove", false, "Remove your reaction instead of adding it")
+}
+
+func runReact(cmd *cobra.Command, args []string) error {
+	content, err := github.ParseReaction(args[1])
+	if err != nil {
+		return err
+	}
+
+	client := newClient(reactDebug)
+
+	commentID, err := commentArg(args[0], client)
+	if err != nil {
+		return err
+	}
+
+	if reactRemove {
+		if err := client.RemoveReaction(commentID, content); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Removed your %s reaction from comment %d\n", github.ReactionEmoji(content), commentID)
+		return nil
+	}
+
+	created, err := client.AddReaction(commentID, content)
+	if err != nil {
+		return err
+	}
+	if !created {
+		fmt.Printf("Already reacted with %s to comment %d\n", github.ReactionEmoji(content), commentID)
+		return nil
+	}
+	fmt.Printf("✅ Reacted with %s to comment %d\n", github.ReactionEmoji(content), commentID)
+	return nil
+}
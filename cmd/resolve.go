@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"path"
 	"strconv"
 	"strings"
 
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
 	"github.com/chmouel/gh-prreview/pkg/github"
 	"github.com/chmouel/gh-prreview/pkg/ui"
 	"github.com/spf13/cobra"
@@ -16,97 +18,237 @@ var (
 	resolveUnresolve bool
 	resolveDebug     bool
 	resolveAll       bool
+	resolveAuthors   []string
+	resolvePaths     []string
+	resolveOutdated  bool
+	resolveMineLast  bool
+	resolveApplied   bool
+	resolveDryRun    bool
+	resolveReply     string
 )
 
 var resolveCmd = &cobra.Command{
-	Use:   "resolve [PR_NUMBER] [COMMENT_ID]",
+	Use:   "resolve [PR] [COMMENT_ID]",
 	Short: "Resolve or unresolve review comment threads",
-	Long:  `Mark review comment threads as resolved or unresolved. Use --all to apply the action to all unresolved comments on a PR.`,
-	Args:  cobra.MinimumNArgs(0),
-	RunE:  runResolve,
+	Long: `Mark review comment threads as resolved or unresolved. Use --all to apply the action to all unresolved comments on a PR.
+
+The filters --author, --path, --outdated, --mine-last and --applied narrow --all down to the
+matching threads, and imply it. --reply posts a closing reply on each thread before its
+state changes; --dry-run only lists the threads that would be changed.
+
+PR is a number, a PR URL, [HOST/]OWNER/REPO#NUMBER or a branch name. The URL of a review
+comment (ending in #discussion_r<ID>) can be given instead of both arguments.`,
+	Example: `  gh prreview resolve --author coderabbitai --outdated --dry-run
+  gh prreview resolve 123 --path 'docs/**' --reply "Done, thanks!"
+  gh prreview resolve --applied --mine-last`,
+	Args: cobra.MinimumNArgs(0),
+	RunE: runResolve,
 }
 
 func init() {
 	resolveCmd.Flags().BoolVar(&resolveUnresolve, "unresolve", false, "Mark the thread as unresolved instead of resolved")
 	resolveCmd.Flags().BoolVar(&resolveDebug, "debug", false, "Enable debug output")
 	resolveCmd.Flags().BoolVar(&resolveAll, "all", false, "Apply action to all unresolved comments on the PR")
+	resolveCmd.Flags().StringSliceVar(&resolveAuthors, "author", nil, "Only threads started by this reviewer (repeatable)")
+	resolveCmd.Flags().StringSliceVar(&resolvePaths, "path", nil, "Only threads on files matching this glob, e.g. 'docs/**' or '*.md' (repeatable)")
+	resolveCmd.Flags().BoolVar(&resolveOutdated, "outdated", false, "Only outdated threads, whose code changed since the comment")
+	resolveCmd.Flags().BoolVar(&resolveMineLast, "mine-last", false, "Only threads whose last comment is yours")
+	resolveCmd.Flags().BoolVar(&resolveApplied, "applied", false, "Only threads whose suggested code is already in the local file")
+	resolveCmd.Flags().BoolVar(&resolveDryRun, "dry-run", false, "List the threads that would be changed without changing them")
+	resolveCmd.Flags().StringVar(&resolveReply, "reply", "", "Reply posted on each thread before resolving it")
 }
 
 func runResolve(cmd *cobra.Command, args []string) error {
-	client := github.NewClient()
-	client.SetDebug(resolveDebug)
-	if repoFlag != "" {
-		client.SetRepo(repoFlag)
+	client := newClient(resolveDebug)
+
+	ref, err := getPRRef(args, client)
+	if err != nil {
+		return err
 	}
+	prNumber := ref.Number
 
-	var prNumber int
-	var err error
+	// Handle --all flag, which the filters imply
+	if resolveAll || hasResolveFilters() {
+		filter, err := newResolveFilter(client)
+		if err != nil {
+			return err
+		}
+		return resolveAllComments(client, prNumber, filter)
+	}
 
-	// Determine PR number
-	if len(args) == 0 {
-		// No arguments provided, get PR from current branch
-		prNumber, err = client.GetCurrentBranchPR()
+	// Handle individual comment resolution
+	commentID := ref.CommentID
+	if len(args) > 1 {
+		commentID, err = parseCommentID(args[1])
 		if err != nil {
 			return err
 		}
-	} else {
-		// First argument is PR number
-		prNumber, err = strconv.Atoi(args[0])
+	}
+	if commentID == 0 {
+		return fmt.Errorf("comment ID is required when not using --all flag")
+	}
+
+	return resolveIndividualComment(client, prNumber, commentID)
+}
+
+// parseCommentID accepts a review comment ID or URL
+func parseCommentID(s string) (int64, error) {
+	if id, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return id, nil
+	}
+	if ref, err := github.ParsePRRef(s); err == nil && ref.CommentID != 0 {
+		return ref.CommentID, nil
+	}
+	return 0, fmt.Errorf("invalid comment ID: %s", s)
+}
+
+// resolveFilter selects the threads changed by a bulk resolve
+type resolveFilter struct {
+	authors  []string
+	paths    []string
+	outdated bool
+	lastBy   string // login the last comment must be from, when set
+	applied  bool
+}
+
+func hasResolveFilters() bool {
+	return len(resolveAuthors) > 0 || len(resolvePaths) > 0 || resolveOutdated || resolveMineLast || resolveApplied
+}
+
+// newResolveFilter builds the filter of the command line flags
+func newResolveFilter(client *github.Client) (*resolveFilter, error) {
+	filter := &resolveFilter{
+		authors:  resolveAuthors,
+		paths:    resolvePaths,
+		outdated: resolveOutdated,
+		applied:  resolveApplied,
+	}
+	for _, pattern := range filter.paths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid --path glob %q: %w", pattern, err)
+		}
+	}
+	if resolveMineLast {
+		login, err := client.CurrentUser()
 		if err != nil {
-			return fmt.Errorf("invalid PR number: %s", args[0])
+			return nil, err
 		}
+		filter.lastBy = login
 	}
+	return filter, nil
+}
 
-	// Handle --all flag
-	if resolveAll {
-		return resolveAllComments(client, prNumber)
+// matches reports whether a thread passes every filter set
+func (f *resolveFilter) matches(comment *github.ReviewComment) bool {
+	if len(f.authors) > 0 && !containsLogin(f.authors, comment.Author) {
+		return false
+	}
+	if len(f.paths) > 0 && !matchesAnyPath(f.paths, comment.Path) {
+		return false
 	}
+	if f.outdated && !comment.IsOutdated {
+		return false
+	}
+	if f.lastBy != "" {
+		last := comment.Author
+		if n := len(comment.ThreadComments); n > 0 {
+			last = comment.ThreadComments[n-1].Author
+		}
+		if !sameLogin(last, f.lastBy) {
+			return false
+		}
+	}
+	if f.applied {
+		applied, err := codecontext.SuggestionApplied(comment)
+		if err != nil && resolveDebug {
+			fmt.Fprintf(os.Stderr, "[DEBUG] Could not check the suggestion of comment %d: %v\n", comment.ID, err)
+		}
+		if !applied {
+			return false
+		}
+	}
+	return true
+}
 
-	// Handle individual comment resolution
-	if len(args) < 2 {
-		return fmt.Errorf("comment ID is required when not using --all flag")
+func containsLogin(logins []string, login string) bool {
+	for _, l := range logins {
+		if sameLogin(l, login) {
+			return true
+		}
 	}
+	return false
+}
 
-	commentID, err := strconv.ParseInt(args[1], 10, 64)
-	if err != nil {
-		return fmt.Errorf("invalid comment ID: %s", args[1])
+// sameLogin compares logins the way users type them: without @, and without the [bot]
+// suffix REST gives bots and GraphQL leaves out
+func sameLogin(a, b string) bool {
+	normalize := func(login string) string {
+		return strings.TrimSuffix(strings.TrimPrefix(login, "@"), "[bot]")
 	}
+	return strings.EqualFold(normalize(a), normalize(b))
+}
 
-	return resolveIndividualComment(client, prNumber, commentID)
+// matchesAnyPath matches a file against globs. A glob without a slash also matches the
+// base name, and a trailing /** matches everything below a directory.
+func matchesAnyPath(patterns []string, file string) bool {
+	for _, pattern := range patterns {
+		if dir, ok := strings.CutSuffix(pattern, "/**"); ok && strings.HasPrefix(file, dir+"/") {
+			return true
+		}
+		if ok, _ := path.Match(pattern, file); ok {
+			return true
+		}
+		if !strings.Contains(pattern, "/") {
+			if ok, _ := path.Match(pattern, path.Base(file)); ok {
+				return true
+			}
+		}
+	}
+	return false
 }
 
-func resolveAllComments(client *github.Client, prNumber int) error {
+func resolveAllComments(client *github.Client, prNumber int, filter *resolveFilter) error {
 	// Fetch all review comments
 	comments, err := client.FetchReviewComments(prNumber)
 	if err != nil {
 		return fmt.Errorf("failed to fetch review comments: %w", err)
 	}
 
-	// Filter unresolved comments
-	var unresolvedComments []*github.ReviewComment
+	// Unresolving applies to the resolved threads
+	state := "unresolved"
+	if resolveUnresolve {
+		state = "resolved"
+	}
+
+	// Filter the threads to change
+	var targetComments []*github.ReviewComment
 	for _, comment := range comments {
-		if !comment.IsResolved() {
-			unresolvedComments = append(unresolvedComments, comment)
+		if comment.IsResolved() == resolveUnresolve && filter.matches(comment) {
+			targetComments = append(targetComments, comment)
 		}
 	}
 
-	if len(unresolvedComments) == 0 {
-		fmt.Printf("No unresolved comments found in %s\n",
-			ui.CreateHyperlink(fmt.Sprintf("https://github.com/%s/pull/%d", getRepoFromClient(client), prNumber),
+	qualifier := ""
+	if hasResolveFilters() {
+		qualifier = " matching"
+	}
+
+	if len(targetComments) == 0 {
+		fmt.Printf("No%s %s comments found in %s\n", qualifier, state,
+			ui.CreateHyperlink(pullRequestURL(client, prNumber),
 				ui.Colorize(ui.ColorCyan, fmt.Sprintf("PR #%d", prNumber))))
 		return nil
 	}
 
 	// Show summary and ask for confirmation
-	prLink := ui.CreateHyperlink(fmt.Sprintf("https://github.com/%s/pull/%d", getRepoFromClient(client), prNumber),
+	prLink := ui.CreateHyperlink(pullRequestURL(client, prNumber),
 		ui.Colorize(ui.ColorCyan, fmt.Sprintf("PR #%d", prNumber)))
-	fmt.Printf("Found %s unresolved comment(s) in %s:\n",
-		ui.Colorize(ui.ColorYellow, fmt.Sprintf("%d", len(unresolvedComments))), prLink)
+	fmt.Printf("Found %s%s %s comment(s) in %s:\n",
+		ui.Colorize(ui.ColorYellow, fmt.Sprintf("%d", len(targetComments))), qualifier, state, prLink)
 
-	for _, comment := range unresolvedComments {
+	for _, comment := range targetComments {
 		// Create clickable link to the review comment
-		fileLocation := fmt.Sprintf("%s:%d", comment.Path, comment.Line)
-		clickableLocation := ui.CreateHyperlink(comment.HTMLURL, fileLocation)
+		clickableLocation := ui.CreateHyperlink(comment.HTMLURL, comment.Location())
 
 		// Truncate comment body and colorize it
 		commentPreview := truncateString(ui.StripSuggestionBlock(comment.Body), 50)
@@ -127,9 +269,18 @@ func resolveAllComments(client *github.Client, prNumber int) error {
 		actionColor = ui.ColorYellow
 	}
 
+	if resolveDryRun {
+		fmt.Printf("\nDry run: would %s %d thread(s)", action, len(targetComments))
+		if resolveReply != "" {
+			fmt.Printf(", replying to each first")
+		}
+		fmt.Println()
+		return nil
+	}
+
 	fmt.Printf("\n%s all %s comment(s)? [y/N]: ",
 		ui.Colorize(actionColor, fmt.Sprintf("Are you sure you want to %s", action)),
-		ui.Colorize(ui.ColorYellow, fmt.Sprintf("%d", len(unresolvedComments))))
+		ui.Colorize(ui.ColorYellow, fmt.Sprintf("%d", len(targetComments))))
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -146,9 +297,20 @@ func resolveAllComments(client *github.Client, prNumber int) error {
 	successCount := 0
 	errorCount := 0
 
-	for _, comment := range unresolvedComments {
+	for _, comment := range targetComments {
 		commentLink := ui.CreateHyperlink(comment.HTMLURL, fmt.Sprintf("Comment %d", comment.ID))
 
+		if resolveReply != "" {
+			if _, err := client.ReplyToComment(prNumber, comment.ID, resolveReply); err != nil {
+				fmt.Printf("%s Failed to reply to %s: %v\n",
+					ui.Colorize(ui.ColorRed, "❌"),
+					ui.Colorize(ui.ColorCyan, commentLink),
+					ui.Colorize(ui.ColorRed, err.Error()))
+				errorCount++
+				continue
+			}
+		}
+
 		if resolveUnresolve {
 			if err := client.UnresolveThread(comment.ThreadID); err != nil {
 				fmt.Printf("%s Failed to unresolve %s: %v\n",
@@ -206,8 +368,26 @@ func resolveIndividualComment(client *github.Client, prNumber int, commentID int
 	}
 
 	// Resolve or unresolve the thread
-	commentLink := ui.CreateHyperlink(fmt.Sprintf("https://github.com/%s/pull/%d#discussion_r%d", getRepoFromClient(client), prNumber, commentID),
-		fmt.Sprintf("Comment %d", commentID))
+	commentURL := ""
+	if prURL := pullRequestURL(client, prNumber); prURL != "" {
+		commentURL = fmt.Sprintf("%s#discussion_r%d", prURL, commentID)
+	}
+	commentLink := ui.CreateHyperlink(commentURL, fmt.Sprintf("Comment %d", commentID))
+
+	if resolveDryRun {
+		action := "resolve"
+		if resolveUnresolve {
+			action = "unresolve"
+		}
+		fmt.Printf("Dry run: would %s the thread for %s\n", action, ui.Colorize(ui.ColorCyan, commentLink))
+		return nil
+	}
+
+	if resolveReply != "" {
+		if _, err := client.ReplyToComment(prNumber, commentID, resolveReply); err != nil {
+			return err
+		}
+	}
 
 	if resolveUnresolve {
 		if err := client.UnresolveThread(threadID); err != nil {
@@ -236,20 +416,21 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen-3] + "..."
 }
 
-// getRepoFromClient extracts the repository name from the client
-func getRepoFromClient(client *github.Client) string {
-	// Use the global repoFlag if set
-	if repoFlag != "" {
-		return repoFlag
+// pullRequestURL returns the web URL of a pull request, on the host of the repository, or ""
+// when the repository is unknown so that no link is shown
+func pullRequestURL(client *github.Client, prNumber int) string {
+	repoURL, err := client.RepoURL()
+	if err != nil {
+		return ""
 	}
+	return fmt.Sprintf("%s/pull/%d", repoURL, prNumber)
+}
 
-	// Try to get repo from the client's internal method
-	// We'll need to call the client's getRepo method
-	repo, err := client.GetRepo()
-	if err == nil && repo != "" {
-		return repo
+// pullRequestArg returns a PR argument for the commands printed as hints: the URL of the pull
+// request when known, else its number
+func pullRequestArg(client *github.Client, prNumber int) string {
+	if prURL := pullRequestURL(client, prNumber); prURL != "" {
+		return prURL
 	}
-
-	// Fallback - we'll construct the URL without the repo part
-	return "owner/repo"
+	return strconv.Itoa(prNumber)
 }
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func TestResolveFilterMatches(t *testing.T) {
+	bot := &github.ReviewComment{Author: "coderabbitai[bot]", Path: "pkg/a.go", IsOutdated: true}
+	replied := &github.ReviewComment{
+		Author: "alice",
+		Path:   "cmd/b.go",
+		ThreadComments: []github.ThreadComment{
+			{Author: "alice"},
+			{Author: "coderabbitai"}, // GraphQL leaves out [bot]
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filter  resolveFilter
+		comment *github.ReviewComment
+		want    bool
+	}{
+		{"no filter", resolveFilter{}, replied, true},
+		{"bot author without [bot]", resolveFilter{authors: []string{"coderabbitai"}, outdated: true}, bot, true},
+		{"bot author with [bot]", resolveFilter{authors: []string{"@CodeRabbitAI[bot]"}}, bot, true},
+		{"other author", resolveFilter{authors: []string{"bob"}}, bot, false},
+		{"not outdated", resolveFilter{outdated: true}, replied, false},
+		{"path", resolveFilter{paths: []string{"cmd/**"}}, replied, true},
+		{"other path", resolveFilter{paths: []string{"cmd/**"}}, bot, false},
+		{"last reply by the bot, REST login", resolveFilter{lastBy: "coderabbitai[bot]"}, replied, true},
+		{"no reply, GraphQL login", resolveFilter{lastBy: "coderabbitai"}, bot, true},
+		{"last reply by someone else", resolveFilter{lastBy: "alice"}, replied, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.matches(tt.comment); got != tt.want {
+				t.Errorf("matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchesAnyPath(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		file     string
+		want     bool
+	}{
+		{[]string{"pkg/github/client.go"}, "pkg/github/client.go", true},
+		{[]string{"pkg/*/client.go"}, "pkg/github/client.go", true},
+		{[]string{"pkg/*.go"}, "pkg/github/client.go", false},
+		// A glob without a slash matches the base name
+		{[]string{"*_test.go"}, "pkg/github/client_test.go", true},
+		{[]string{"*_test.go"}, "pkg/github/client.go", false},
+		// A trailing /** matches everything below the directory
+		{[]string{"pkg/**"}, "pkg/github/client.go", true},
+		{[]string{"pkg/**"}, "pkgs/client.go", false},
+		{[]string{"cmd/**", "*.md"}, "docs/README.md", true},
+		{nil, "main.go", false},
+	}
+	for _, tt := range tests {
+		if got := matchesAnyPath(tt.patterns, tt.file); got != tt.want {
+			t.Errorf("matchesAnyPath(%q, %q) = %v, want %v", tt.patterns, tt.file, got, tt.want)
+		}
+	}
+}
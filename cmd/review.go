@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	reviewDebug  bool
+	reviewEvent  string
+	reviewBody   string
+	reviewEditor bool
+)
+
+var reviewCmd = &cobra.Command{
+	Use:   "review",
+	Short: "Submit your review or look at your pending one",
+	Long: `Finish a review from the terminal: list the comments of your pending review, added with
+suggest or comment --pending, and submit it as an approval, a change request or a comment.`,
+}
+
+var reviewSubmitCmd = &cobra.Command{
+	Use:   "submit [PR]",
+	Short: "Submit your pending review",
+	Long: `Submit your pending review of a pull request with an event and a summary body. Without a
+pending review, a review without inline comments is created, e.g. to approve.`,
+	Example: `  gh prreview review submit --event approve
+  gh prreview review submit 123 --event request-changes --body "See the comments inline"
+  gh prreview review submit --event comment --edit`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runReviewSubmit,
+}
+
+var reviewPendingCmd = &cobra.Command{
+	Use:   "pending [PR]",
+	Short: "List the comments of your pending review",
+	Args:  cobra.MaximumNArgs(1),
+	RunE:  runReviewPending,
+}
+
+func init() {
+	reviewCmd.PersistentFlags().BoolVar(&reviewDebug, "debug", false, "Enable debug output")
+	reviewSubmitCmd.Flags().StringVarP(&reviewEvent, "event", "e", "comment", "Review event: approve, request-changes or comment")
+	reviewSubmitCmd.Flags().StringVarP(&reviewBody, "body", "b", "", "Summary comment of the review")
+	reviewSubmitCmd.Flags().BoolVar(&reviewEditor, "edit", false, "Write the summary comment in $EDITOR")
+	reviewCmd.AddCommand(reviewSubmitCmd)
+	reviewCmd.AddCommand(reviewPendingCmd)
+}
+
+func runReviewSubmit(cmd *cobra.Command, args []string) error {
+	event, err := parseReviewEvent(reviewEvent)
+	if err != nil {
+		return err
+	}
+
+	client := newClient(reviewDebug)
+
+	prNumber, err := getPRNumber(args, client)
+	if err != nil {
+		return err
+	}
+
+	body := reviewBody
+	if reviewEditor {
+		if body, err = ui.EditText(reviewBody); err != nil {
+			return err
+		}
+	}
+
+	pending, err := client.PendingReview(prNumber)
+	if err != nil {
+		return err
+	}
+
+	prLink := ui.CreateHyperlink(pullRequestURL(client, prNumber),
+		ui.Colorize(ui.ColorCyan, fmt.Sprintf("PR #%d", prNumber)))
+
+	if pending == nil {
+		if event != "APPROVE" && strings.TrimSpace(body) == "" {
+			return fmt.Errorf("no pending review on PR #%d; a review without inline comments needs a --body", prNumber)
+		}
+		pr, err := client.GetPullRequest(prNumber)
+		if err != nil {
+			return err
+		}
+		review, err := client.CreateReview(prNumber, pr.HeadSHA, body, event, nil)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Reviewed %s: %s\n", prLink, reviewStateLabel(review.State))
+		return nil
+	}
+
+	comments, err := client.FetchReviewCommentsOfReview(prNumber, pending.ID)
+	if err != nil {
+		return err
+	}
+
+	review, err := client.SubmitReview(prNumber, pending.ID, event, body)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Submitted your review of %s with %d comment(s): %s\n", prLink, len(comments), reviewStateLabel(review.State))
+	return nil
+}
+
+func runReviewPending(cmd *cobra.Command, args []string) error {
+	client := newClient(reviewDebug)
+
+	prNumber, err := getPRNumber(args, client)
+	if err != nil {
+		return err
+	}
+
+	pending, err := client.PendingReview(prNumber)
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		fmt.Printf("You have no pending review on PR #%d.\n", prNumber)
+		return nil
+	}
+
+	comments, err := client.FetchReviewCommentsOfReview(prNumber, pending.ID)
+	if err != nil {
+		return err
+	}
+
+	header := fmt.Sprintf("Pending review of PR #%d: %d comment(s)", prNumber, len(comments))
+	fmt.Printf("%s\n", ui.Colorize(ui.ColorYellow, header))
+	if body := strings.TrimSpace(pending.Body); body != "" {
+		fmt.Printf("\n%s\n", ui.WrapText(body, 80))
+	}
+	for i, comment := range comments {
+		displayComment(i+1, len(comments), comment, suggestionApplied(comment))
+	}
+
+	fmt.Printf("\n%s\n", ui.Colorize(ui.ColorGray, "Submit it with: gh prreview review submit --event approve|request-changes|comment"))
+	return nil
+}
@@ -1,11 +1,19 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
 	"github.com/spf13/cobra"
 )
 
 var (
-	repoFlag string
+	repoFlag     string
+	hostnameFlag string
+	styleFlag    string
+	offlineFlag  bool
+	noCacheFlag  bool
 )
 
 var rootCmd = &cobra.Command{
@@ -13,6 +21,34 @@ var rootCmd = &cobra.Command{
 	Short: "Apply GitHub review comments directly to your code",
 	Long: `gh-prreview is a GitHub CLI extension that allows you to fetch and apply
 review comments and suggestions from pull requests directly to your local code.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if offlineFlag && noCacheFlag {
+			return fmt.Errorf("--offline needs the cache and cannot be combined with --no-cache")
+		}
+		if styleFlag != "" {
+			ui.SetHighlightStyle(styleFlag)
+		}
+		return nil
+	},
+}
+
+// newClient returns a GitHub client set up from the global flags
+func newClient(debug bool) *github.Client {
+	client := github.NewClient()
+	client.SetDebug(debug)
+	if repoFlag != "" {
+		client.SetRepo(repoFlag)
+	}
+	if hostnameFlag != "" {
+		client.SetHost(hostnameFlag)
+	}
+	if !noCacheFlag {
+		if dir, err := github.DefaultCacheDir(); err == nil {
+			client.SetCache(github.NewCache(dir))
+		}
+	}
+	client.SetOffline(offlineFlag)
+	return client
 }
 
 func Execute() error {
@@ -20,8 +56,22 @@ func Execute() error {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "R", "", "Select a repository using the OWNER/REPO format")
+	rootCmd.PersistentFlags().StringVarP(&repoFlag, "repo", "R", "", "Select a repository using the [HOST/]OWNER/REPO format")
+	rootCmd.PersistentFlags().StringVar(&hostnameFlag, "hostname", "", "GitHub host to use, e.g. a GitHub Enterprise Server (defaults to $GH_HOST, then the host of the git remote)")
+	rootCmd.PersistentFlags().StringVar(&styleFlag, "style", "", "Syntax highlighting style (a chroma style name, or \"none\"); defaults to $GH_PRREVIEW_STYLE")
+	rootCmd.PersistentFlags().BoolVar(&offlineFlag, "offline", false, "Use the review data cached by the last run instead of GitHub")
+	rootCmd.PersistentFlags().BoolVar(&noCacheFlag, "no-cache", false, "Do not read or update the review data cache")
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(applyCmd)
 	rootCmd.AddCommand(resolveCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(mcpCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(suggestCmd)
+	rootCmd.AddCommand(commentCmd)
+	rootCmd.AddCommand(reviewCmd)
+	rootCmd.AddCommand(editCmd)
+	rootCmd.AddCommand(deleteCmd)
+	rootCmd.AddCommand(reactCmd)
+	rootCmd.AddCommand(finishCmd)
 }
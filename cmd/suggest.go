@@ -0,0 +1,244 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/suggest"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/spf13/cobra"
+)
+
+var (
+	suggestDebug  bool
+	suggestYes    bool
+	suggestDryRun bool
+	suggestBody   string
+)
+
+var suggestCmd = &cobra.Command{
+	Use:   "suggest [PR]",
+	Short: "Turn local edits into review suggestions",
+	Long: `The reverse of apply: diff the working tree against the head of the pull request and
+post each changed region as a suggestion in your pending review, started if you have none,
+which you then submit with review submit or on GitHub.
+
+Only lines that are part of the pull request diff can carry a suggestion; other changes are
+listed and skipped. Each suggestion can be given a comment interactively before it is posted.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runSuggest,
+}
+
+func init() {
+	suggestCmd.Flags().BoolVar(&suggestDebug, "debug", false, "Enable debug output")
+	suggestCmd.Flags().BoolVarP(&suggestYes, "yes", "y", false, "Post every suggestion without prompting for comments")
+	suggestCmd.Flags().BoolVar(&suggestDryRun, "dry-run", false, "Show the suggestions without creating the review")
+	suggestCmd.Flags().StringVarP(&suggestBody, "body", "b", "", "Summary comment of the pending review")
+}
+
+func runSuggest(cmd *cobra.Command, args []string) error {
+	client := newClient(suggestDebug)
+
+	prNumber, err := getPRNumber(args, client)
+	if err != nil {
+		return err
+	}
+
+	pr, err := client.GetPullRequest(prNumber)
+	if err != nil {
+		return err
+	}
+
+	// Only one pending review is allowed, the suggestions are added to ours if there is one
+	pending, err := client.PendingReview(prNumber)
+	if err != nil {
+		return err
+	}
+	if pending != nil && suggestBody != "" {
+		return fmt.Errorf("you have a pending review on PR #%d; set its summary when submitting it with: gh prreview review submit --body", prNumber)
+	}
+
+	if _, err := gitCommand("cat-file", "-e", pr.HeadSHA+"^{commit}"); err != nil {
+		return fmt.Errorf("the head of PR #%d (%s) is not available locally; fetch it first, e.g. with: gh pr checkout %d", prNumber, shortSHA(pr.HeadSHA), prNumber)
+	}
+
+	diff, err := gitCommand("diff", "--no-color", "--no-ext-diff", "--no-renames", "--src-prefix=a/", "--dst-prefix=b/", "-U0", pr.HeadSHA)
+	if err != nil {
+		return fmt.Errorf("failed to diff the working tree: %w", err)
+	}
+	files := suggest.ParseGitDiff(diff)
+	if len(files) == 0 {
+		fmt.Printf("No local changes against the head of PR #%d (%s)\n", prNumber, shortSHA(pr.HeadSHA))
+		return nil
+	}
+
+	prFiles, err := client.FetchPullRequestFiles(prNumber)
+	if err != nil {
+		return err
+	}
+	prPatches := make(map[string]string, len(prFiles))
+	for _, file := range prFiles {
+		prPatches[file.Path] = file.Patch
+	}
+
+	headLines := func(path string) ([]string, error) {
+		content, err := gitCommand("show", pr.HeadSHA+":"+path)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), nil
+	}
+
+	suggestions, skipped, err := suggest.Build(files, prPatches, headLines)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range skipped {
+		location := s.Path
+		if s.StartLine > 0 {
+			location = fmt.Sprintf("%s:%d-%d", s.Path, s.StartLine, s.EndLine)
+		}
+		fmt.Fprintf(os.Stderr, "%s %s: %s\n", ui.Colorize(ui.ColorYellow, "Skipped"), location, s.Reason)
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("No local change can be posted as a suggestion.")
+		return nil
+	}
+
+	comments, err := collectSuggestions(suggestions)
+	if err != nil {
+		return err
+	}
+	if len(comments) == 0 {
+		fmt.Println("No suggestion selected, nothing to post.")
+		return nil
+	}
+
+	if suggestDryRun {
+		for _, comment := range comments {
+			fmt.Printf("\n%s\n%s\n", ui.Colorize(ui.ColorCyan, draftLocation(comment)), comment.Body)
+		}
+		if pending != nil {
+			fmt.Printf("\nDry run: would add %d suggestion(s) to your pending review on PR #%d\n", len(comments), prNumber)
+		} else {
+			fmt.Printf("\nDry run: would create a pending review with %d suggestion(s) on PR #%d\n", len(comments), prNumber)
+		}
+		return nil
+	}
+
+	prLink := ui.CreateHyperlink(pr.HTMLURL, ui.Colorize(ui.ColorCyan, fmt.Sprintf("PR #%d", prNumber)))
+
+	if pending != nil {
+		for i, comment := range comments {
+			if err := client.AddPendingReviewComment(pending.NodeID, comment); err != nil {
+				return fmt.Errorf("added %d of %d suggestion(s) to your pending review: %w", i, len(comments), err)
+			}
+		}
+		fmt.Printf("✅ Added %d suggestion(s) to your pending review on %s\n", len(comments), prLink)
+	} else {
+		if _, err := client.CreateReview(prNumber, pr.HeadSHA, suggestBody, "", comments); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Created a pending review with %d suggestion(s) on %s\n", len(comments), prLink)
+	}
+	fmt.Println("Review it with: gh prreview review pending")
+	fmt.Println("Submit it with: gh prreview review submit --event approve|request-changes|comment")
+	return nil
+}
+
+// collectSuggestions shows each suggestion and asks whether to post it and with what
+// comment, unless --yes or --dry-run is set
+func collectSuggestions(suggestions []*suggest.Suggestion) ([]github.DraftReviewComment, error) {
+	comments := make([]github.DraftReviewComment, 0, len(suggestions))
+	if suggestYes || suggestDryRun {
+		for _, s := range suggestions {
+			comments = append(comments, s.Comment(""))
+		}
+		return comments, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for i, s := range suggestions {
+		header := fmt.Sprintf("[%d/%d] %s", i+1, len(suggestions), s.Location())
+		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, header))
+		fmt.Printf("%s\n", ui.Colorize(ui.ColorGray, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
+		fmt.Println(ui.ColorizeDiff(suggestionDiff(s), s.Path))
+
+		fmt.Printf("\n%s ", "Post this suggestion? [y/c/e/s/q] (yes/with comment/comment in $EDITOR/skip/quit)")
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+
+		switch strings.ToLower(strings.TrimSpace(response)) {
+		case "y", "yes":
+			comments = append(comments, s.Comment(""))
+		case "c", "comment":
+			fmt.Printf("%s ", "Comment:")
+			prose, err := reader.ReadString('\n')
+			if err != nil {
+				return nil, fmt.Errorf("failed to read input: %w", err)
+			}
+			comments = append(comments, s.Comment(prose))
+		case "e", "edit":
+			prose, err := ui.EditText("")
+			if err != nil {
+				fmt.Printf("❌ %v\n", err)
+				fmt.Printf("⏭️  Skipped\n")
+				continue
+			}
+			comments = append(comments, s.Comment(prose))
+		case "q", "quit":
+			fmt.Printf("\nStopped after %d of %d suggestion(s)\n", i, len(suggestions))
+			return comments, nil
+		default:
+			fmt.Printf("⏭️  Skipped\n")
+		}
+	}
+	return comments, nil
+}
+
+// suggestionDiff renders a suggestion as a diff hunk of the PR head
+func suggestionDiff(s *suggest.Suggestion) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", s.StartLine, len(s.Original), s.StartLine, len(s.Replacement))
+	for _, line := range s.Original {
+		b.WriteString("-" + line + "\n")
+	}
+	for _, line := range s.Replacement {
+		b.WriteString("+" + line + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+// draftLocation returns path:line or path:start-end of a draft review comment
+func draftLocation(comment github.DraftReviewComment) string {
+	if comment.StartLine > 0 {
+		return fmt.Sprintf("%s:%d-%d", comment.Path, comment.StartLine, comment.Line)
+	}
+	return fmt.Sprintf("%s:%d", comment.Path, comment.Line)
+}
+
+// gitCommand runs a git command and returns its output
+func gitCommand(args ...string) (string, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return string(output), nil
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
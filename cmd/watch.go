@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/chmouel/gh-prreview/pkg/watch"
+	"github.com/spf13/cobra"
+)
+
+const minWatchInterval = 5 * time.Second
+
+var (
+	watchInterval     time.Duration
+	watchResolveEvery int
+	watchNotify       bool
+	watchExec         string
+	watchDebug        bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch [PR]",
+	Short: "Stream new review comments as they arrive",
+	Long: `Poll a pull request and print only what changed since the last poll: new review
+comments, new replies and threads being resolved or unresolved.
+
+Polls use conditional requests, which do not count against the rate limit when
+nothing changed. Resolving a thread does not change its comments, so the thread
+states are re-read every --resolved-check-every polls.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 30*time.Second, "Time between polls")
+	watchCmd.Flags().IntVar(&watchResolveEvery, "resolved-check-every", 4, "Re-read the thread states every N polls even when no comment changed (0 to only re-read on new comments)")
+	watchCmd.Flags().BoolVar(&watchNotify, "notify", false, "Show a desktop notification for each event")
+	watchCmd.Flags().StringVar(&watchExec, "exec", "", "Shell command to run for each event, described in GH_PRREVIEW_* environment variables")
+	watchCmd.Flags().BoolVar(&watchDebug, "debug", false, "Enable debug output")
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	if offlineFlag {
+		return fmt.Errorf("watch polls GitHub and cannot be used with --offline")
+	}
+	if watchInterval < minWatchInterval {
+		return fmt.Errorf("--interval must be at least %s", minWatchInterval)
+	}
+
+	client := newClient(watchDebug)
+
+	prNumber, err := getPRNumber(args, client)
+	if err != nil {
+		return err
+	}
+
+	repo, err := client.GetRepo()
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Take the ETag first so nothing posted while fetching the threads is missed
+	_, etag, err := client.ReviewCommentsChanged(prNumber, "")
+	if err != nil {
+		return err
+	}
+
+	comments, err := client.FetchReviewComments(prNumber)
+	if err != nil {
+		return fmt.Errorf("failed to fetch review comments: %w", err)
+	}
+	snapshot := watch.NewSnapshot(comments)
+
+	unresolved := 0
+	for _, comment := range comments {
+		if !comment.IsResolved() {
+			unresolved++
+		}
+	}
+	fmt.Printf("👀 Watching PR #%d on %s (%d thread(s), %d unresolved), polling every %s. Press Ctrl+C to stop.\n",
+		prNumber, repo, snapshot.Len(), unresolved, watchInterval)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	for polls := 1; ; polls++ {
+		select {
+		case <-ctx.Done():
+			fmt.Println()
+			return nil
+		case <-ticker.C:
+		}
+
+		changed, newETag, err := client.ReviewCommentsChanged(prNumber, etag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", ui.Colorize(ui.ColorYellow, "⚠️"), err)
+			continue
+		}
+		etag = newETag
+
+		if !changed && (watchResolveEvery <= 0 || polls%watchResolveEvery != 0) {
+			continue
+		}
+
+		comments, err := client.FetchReviewComments(prNumber)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s failed to fetch review comments: %v\n", ui.Colorize(ui.ColorYellow, "⚠️"), err)
+			continue
+		}
+
+		next := watch.NewSnapshot(comments)
+		for _, event := range watch.Diff(snapshot, next) {
+			handleWatchEvent(event, repo, prNumber)
+		}
+		snapshot = next
+	}
+}
+
+// handleWatchEvent prints an event and fires the notification and hook for it
+func handleWatchEvent(event watch.Event, repo string, prNumber int) {
+	displayWatchEvent(event)
+
+	if watchNotify {
+		message := ui.StripSuggestionBlock(event.Body())
+		if message == "" {
+			message = event.Thread.Location()
+		}
+		if err := watch.Notify(fmt.Sprintf("PR #%d: %s", prNumber, event.Summary()), truncateString(message, 200)); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", ui.Colorize(ui.ColorYellow, "⚠️"), err)
+		}
+	}
+
+	if watchExec != "" {
+		if err := watch.RunHook(watchExec, event, repo, prNumber); err != nil {
+			fmt.Fprintf(os.Stderr, "%s %v\n", ui.Colorize(ui.ColorYellow, "⚠️"), err)
+		}
+	}
+}
+
+func displayWatchEvent(event watch.Event) {
+	var icon, color string
+	switch event.Type {
+	case watch.EventNewComment:
+		icon, color = "💬", ui.ColorCyan
+	case watch.EventNewReply:
+		icon, color = "↩️ ", ui.ColorCyan
+	case watch.EventResolved:
+		icon, color = "✅", ui.ColorGreen
+	case watch.EventUnresolved:
+		icon, color = "🔄", ui.ColorYellow
+	}
+
+	summary := ui.CreateHyperlink(event.URL(), event.Summary())
+	fmt.Printf("\n%s %s %s\n",
+		ui.Colorize(ui.ColorGray, time.Now().Format("15:04:05")),
+		icon,
+		ui.Colorize(color, summary))
+
+	body := ui.StripSuggestionBlock(event.Body())
+	if body != "" {
+		for _, line := range strings.Split(ui.WrapText(body, 76), "\n") {
+			fmt.Printf("   %s\n", line)
+		}
+	}
+	if event.Type == watch.EventNewComment && event.Thread.HasSuggestion {
+		fmt.Printf("   %s\n", ui.Colorize(ui.ColorYellow, "💡 Includes a suggested change"))
+	}
+}
@@ -6,12 +6,12 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/briandowns/spinner"
 	"github.com/chmouel/gh-prreview/pkg/ai"
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
 	"github.com/chmouel/gh-prreview/pkg/diffhunk"
 	"github.com/chmouel/gh-prreview/pkg/github"
 	"github.com/chmouel/gh-prreview/pkg/ui"
@@ -24,6 +24,8 @@ type Applier struct {
 	debug        bool
 	aiProvider   ai.AIProvider
 	githubClient *github.Client
+	deferResolve bool
+	addressed    []*github.ReviewComment
 }
 
 func New() *Applier {
@@ -45,6 +47,17 @@ func (a *Applier) SetGitHubClient(client *github.Client) {
 	a.githubClient = client
 }
 
+// SetDeferResolve makes the applier record the threads to resolve, see Addressed, instead of
+// resolving them before the fix is even pushed
+func (a *Applier) SetDeferResolve(deferResolve bool) {
+	a.deferResolve = deferResolve
+}
+
+// Addressed returns the threads to resolve once the fixes are pushed, with SetDeferResolve
+func (a *Applier) Addressed() []*github.ReviewComment {
+	return a.addressed
+}
+
 // debugLog prints debug messages if debug mode is enabled
 func (a *Applier) debugLog(format string, args ...interface{}) {
 	if a.debug {
@@ -59,13 +72,16 @@ func (a *Applier) ApplyAll(suggestions []*github.ReviewComment) error {
 
 	for _, suggestion := range suggestions {
 		if err := a.applySuggestion(suggestion); err != nil {
-			fmt.Printf("❌ Failed to apply suggestion for %s:%d: %v\n",
-				suggestion.Path, suggestion.Line, err)
+			fmt.Printf("❌ Failed to apply suggestion for %s: %v\n",
+				suggestion.Location(), err)
 			failed++
 		} else {
-			fmt.Printf("✅ Applied suggestion to %s:%d\n",
-				suggestion.Path, suggestion.Line)
+			fmt.Printf("✅ Applied suggestion to %s\n",
+				suggestion.Location())
 			applied++
+			if a.deferResolve && suggestion.ThreadID != "" && !suggestion.IsResolved() {
+				a.addressed = append(a.addressed, suggestion)
+			}
 
 			// Show git diff of what was applied
 			a.showGitDiff(suggestion.Path)
@@ -84,8 +100,7 @@ func (a *Applier) ApplyInteractive(suggestions []*github.ReviewComment) error {
 
 	for i, suggestion := range suggestions {
 		// Create clickable link to the review comment
-		fileLocation := fmt.Sprintf("%s:%d", suggestion.Path, suggestion.Line)
-		clickableLocation := ui.CreateHyperlink(suggestion.HTMLURL, fileLocation)
+		clickableLocation := ui.CreateHyperlink(suggestion.HTMLURL, suggestion.Location())
 
 		// Show header with outdated warning if applicable
 		header := fmt.Sprintf("[%d/%d] %s by @%s", i+1, len(suggestions), clickableLocation, suggestion.Author)
@@ -107,15 +122,33 @@ func (a *Applier) ApplyInteractive(suggestions []*github.ReviewComment) error {
 				fmt.Printf("%s\n", wrappedComment)
 			}
 		}
+		if reactions := suggestion.Reactions.String(); reactions != "" {
+			fmt.Printf("%s\n", reactions)
+		}
+
+		// Show the whole range of code a multi-line suggestion replaces
+		if suggestion.IsMultiLine() {
+			if snippet, err := codecontext.ForComment(suggestion, 0); err == nil {
+				title := "Lines to replace:"
+				if snippet.FromHunk {
+					title = "Lines to replace (from diff hunk):"
+				}
+				fmt.Printf("\n%s\n", title)
+				first, lines := snippet.Code()
+				fmt.Println(ui.FormatCodeLines(first, lines, snippet.Start, snippet.End, suggestion.Path))
+			} else {
+				a.debugLog("Could not load commented lines: %v", err)
+			}
+		}
 
 		// Show the suggestion
 		fmt.Printf("\n%s\n", "Suggested change:")
-		fmt.Println(ui.ColorizeCode(suggestion.SuggestedCode))
+		fmt.Println(ui.ColorizeCode(suggestion.SuggestedCode, suggestion.Path))
 
 		// Show context if available
 		if suggestion.DiffHunk != "" {
 			fmt.Printf("\n%s\n", "Context:")
-			fmt.Println(ui.ColorizeDiff(suggestion.DiffHunk))
+			fmt.Println(ui.ColorizeDiff(suggestion.DiffHunk, suggestion.Path))
 		}
 
 		// Show thread comments (replies)
@@ -138,22 +171,46 @@ func (a *Applier) ApplyInteractive(suggestions []*github.ReviewComment) error {
 						fmt.Printf("     %s\n", line)
 					}
 				}
+				if reactions := threadComment.Reactions.String(); reactions != "" {
+					fmt.Printf("     %s\n", reactions)
+				}
 			}
 		}
 
-		// Update prompt based on AI availability
-		prompt := "Apply this suggestion? [y/s/q] (yes/skip/quit)"
-		if a.aiProvider != nil {
-			prompt = "Apply this suggestion? [y/s/a/q] (yes/skip/ai-apply/quit)"
-		}
-		fmt.Printf("\n%s ", prompt)
+		// Reacting, or editing or deleting our own comments of the thread, prompts again
+		own := a.ownComments(suggestion)
+		var response string
+		deleted := false
+		for {
+			fmt.Printf("\n%s ", a.interactivePrompt(len(own) > 0))
 
-		response, err := reader.ReadString('\n')
-		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return fmt.Errorf("failed to read input: %w", err)
+			}
+			response = strings.ToLower(strings.TrimSpace(line))
+
+			if response == "r" && a.githubClient != nil {
+				a.reactToComment(reader, suggestion)
+				continue
+			}
+			if len(own) == 0 || (response != "e" && response != "d") {
+				break
+			}
+			if response == "e" {
+				a.editOwnComment(reader, suggestion, own)
+			} else if a.deleteOwnComment(reader, suggestion, own) {
+				deleted = true
+				break
+			}
+			own = a.ownComments(suggestion)
 		}
 
-		response = strings.ToLower(strings.TrimSpace(response))
+		if deleted {
+			fmt.Printf("⏭️  Skipped (comment deleted)\n")
+			skipped++
+			continue
+		}
 
 		switch response {
 		case "q", "quit":
@@ -211,6 +268,34 @@ func (a *Applier) ApplyInteractive(suggestions []*github.ReviewComment) error {
 	return nil
 }
 
+// interactivePrompt returns the question asked for each suggestion, listing the actions
+// available
+func (a *Applier) interactivePrompt(hasOwnComments bool) string {
+	keys, actions := "y/s", "yes/skip"
+	if a.aiProvider != nil {
+		keys += "/a"
+		actions += "/ai-apply"
+	}
+	if a.githubClient != nil {
+		keys += "/r"
+		actions += "/react"
+	}
+	if hasOwnComments {
+		keys += "/e/d"
+		actions += "/edit mine/delete mine"
+	}
+	return fmt.Sprintf("Apply this suggestion? [%s/q] (%s/quit)", keys, actions)
+}
+
+// ApplySuggestion applies a single suggestion to the working tree without prompting or
+// printing anything
+func (a *Applier) ApplySuggestion(comment *github.ReviewComment) error {
+	if !comment.HasSuggestion {
+		return fmt.Errorf("comment %d has no suggestion", comment.ID)
+	}
+	return a.applySuggestion(comment)
+}
+
 // applySuggestion applies a single suggestion to a file using git apply
 func (a *Applier) applySuggestion(comment *github.ReviewComment) error {
 	// Create a unified diff patch
@@ -561,7 +646,7 @@ func (a *Applier) applyWithAI(comment *github.ReviewComment, autoApply bool) err
 	expectedLines := diffhunk.GetAddedLines(comment.DiffHunk)
 
 	// Detect language from file extension
-	language := detectLanguage(comment.Path)
+	language := ui.DetectLanguage(comment.Path)
 
 	// Build AI request
 	req := &ai.SuggestionRequest{
@@ -610,7 +695,7 @@ func (a *Applier) applyWithAI(comment *github.ReviewComment, autoApply bool) err
 
 	// Show the generated patch
 	fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, "Generated patch:"))
-	fmt.Println(ui.ColorizeDiff(resp.Patch))
+	fmt.Println(ui.ColorizeDiff(resp.Patch, comment.Path))
 
 	a.debugLog("AI-generated patch:\n%s", resp.Patch)
 
@@ -772,7 +857,11 @@ func (a *Applier) promptToResolveThread(comment *github.ReviewComment) {
 		return
 	}
 
-	fmt.Printf("\n%s ", ui.Colorize(ui.ColorYellow, "Mark this review thread as resolved? [y/n]"))
+	question := "Mark this review thread as resolved? [y/n]"
+	if a.deferResolve {
+		question = "Resolve this review thread once the fix is pushed? [y/n]"
+	}
+	fmt.Printf("\n%s ", ui.Colorize(ui.ColorYellow, question))
 	reader := bufio.NewReader(os.Stdin)
 	response, err := reader.ReadString('\n')
 	if err != nil {
@@ -780,7 +869,10 @@ func (a *Applier) promptToResolveThread(comment *github.ReviewComment) {
 	}
 
 	response = strings.ToLower(strings.TrimSpace(response))
-	if response == "y" || response == "yes" {
+	if (response == "y" || response == "yes") && a.deferResolve {
+		a.addressed = append(a.addressed, comment)
+		fmt.Printf("🕓 The thread will be resolved after the push\n")
+	} else if response == "y" || response == "yes" {
 		if err := a.githubClient.ResolveThread(comment.ThreadID); err != nil {
 			fmt.Printf("❌ Failed to resolve thread: %v\n", err)
 		} else {
@@ -800,9 +892,9 @@ func (a *Applier) ApplyAllWithAI(suggestions []*github.ReviewComment) error {
 
 	for _, suggestion := range suggestions {
 		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorGray, "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"))
-		fmt.Printf("%s %s:%d by @%s\n",
+		fmt.Printf("%s %s by @%s\n",
 			ui.Colorize(ui.ColorCyan, "Processing:"),
-			suggestion.Path, suggestion.Line, suggestion.Author)
+			suggestion.Location(), suggestion.Author)
 
 		if err := a.applyWithAI(suggestion, true); err != nil {
 			fmt.Printf("❌ Failed: %v\n", err)
@@ -815,7 +907,9 @@ func (a *Applier) ApplyAllWithAI(suggestions []*github.ReviewComment) error {
 			a.showGitDiff(suggestion.Path)
 
 			// Automatically resolve thread when possible
-			if a.githubClient != nil && suggestion.ThreadID != "" && !suggestion.IsResolved() {
+			if a.deferResolve && suggestion.ThreadID != "" && !suggestion.IsResolved() {
+				a.addressed = append(a.addressed, suggestion)
+			} else if a.githubClient != nil && suggestion.ThreadID != "" && !suggestion.IsResolved() {
 				if err := a.githubClient.ResolveThread(suggestion.ThreadID); err != nil {
 					fmt.Printf("⚠️  Failed to auto-resolve thread: %v\n", err)
 				} else {
@@ -832,46 +926,3 @@ func (a *Applier) ApplyAllWithAI(suggestions []*github.ReviewComment) error {
 		ui.Colorize(ui.ColorRed, fmt.Sprintf("%d", failed)))
 	return nil
 }
-
-// detectLanguage detects programming language from file extension
-func detectLanguage(filePath string) string {
-	ext := filepath.Ext(filePath)
-	switch ext {
-	case ".go":
-		return "go"
-	case ".py":
-		return "python"
-	case ".js":
-		return "javascript"
-	case ".ts":
-		return "typescript"
-	case ".jsx":
-		return "javascript"
-	case ".tsx":
-		return "typescript"
-	case ".java":
-		return "java"
-	case ".rs":
-		return "rust"
-	case ".c":
-		return "c"
-	case ".cpp", ".cc", ".cxx":
-		return "cpp"
-	case ".h", ".hpp":
-		return "cpp"
-	case ".rb":
-		return "ruby"
-	case ".php":
-		return "php"
-	case ".sh":
-		return "bash"
-	case ".md":
-		return "markdown"
-	case ".yaml", ".yml":
-		return "yaml"
-	case ".json":
-		return "json"
-	default:
-		return "unknown"
-	}
-}
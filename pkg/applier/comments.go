@@ -0,0 +1,127 @@
+package applier
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+)
+
+// ownComment is a comment of a thread written by the current user. Index is -1 for the
+// first comment of the thread, else the index of the reply.
+type ownComment struct {
+	Index int
+	ID    int64
+	Body  string
+}
+
+// ownComments returns the comments of the thread of suggestion written by the current user
+func (a *Applier) ownComments(suggestion *github.ReviewComment) []ownComment {
+	if a.githubClient == nil {
+		return nil
+	}
+	login, err := a.githubClient.CurrentUser()
+	if err != nil {
+		a.debugLog("Could not look up the current user: %v", err)
+		return nil
+	}
+
+	var own []ownComment
+	if strings.EqualFold(suggestion.Author, login) {
+		own = append(own, ownComment{Index: -1, ID: suggestion.ID, Body: suggestion.Body})
+	}
+	for i, reply := range suggestion.ThreadComments {
+		if strings.EqualFold(reply.Author, login) {
+			own = append(own, ownComment{Index: i, ID: reply.ID, Body: reply.Body})
+		}
+	}
+	return own
+}
+
+// pickOwnComment asks which of our comments to act on when there are several
+func pickOwnComment(reader *bufio.Reader, own []ownComment, action string) (ownComment, bool) {
+	if len(own) == 1 {
+		return own[0], true
+	}
+
+	fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, "Your comments in this thread:"))
+	for i, comment := range own {
+		fmt.Printf("  %d) %s\n", i+1, ui.Colorize(ui.ColorGray, commentPreview(comment.Body)))
+	}
+	fmt.Printf("%s ", fmt.Sprintf("Which one to %s? [1-%d]", action, len(own)))
+
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return ownComment{}, false
+	}
+	choice, err := strconv.Atoi(strings.TrimSpace(response))
+	if err != nil || choice < 1 || choice > len(own) {
+		fmt.Printf("⏭️  Cancelled\n")
+		return ownComment{}, false
+	}
+	return own[choice-1], true
+}
+
+// editOwnComment edits one of our comments of the thread in $EDITOR
+func (a *Applier) editOwnComment(reader *bufio.Reader, suggestion *github.ReviewComment, own []ownComment) {
+	comment, ok := pickOwnComment(reader, own, "edit")
+	if !ok {
+		return
+	}
+
+	body, err := ui.EditText(comment.Body)
+	if err != nil {
+		fmt.Printf("❌ %v\n", err)
+		return
+	}
+	if strings.TrimSpace(body) == strings.TrimSpace(comment.Body) {
+		fmt.Printf("No changes\n")
+		return
+	}
+
+	if _, err := a.githubClient.EditReviewComment(comment.ID, body); err != nil {
+		fmt.Printf("❌ Failed to edit comment: %v\n", err)
+		return
+	}
+	if comment.Index < 0 {
+		suggestion.Body = body
+	} else {
+		suggestion.ThreadComments[comment.Index].Body = body
+	}
+	fmt.Printf("✅ Comment edited\n")
+}
+
+// deleteOwnComment deletes one of our comments of the thread after confirmation. It returns
+// true when the first comment, and so the suggestion, was deleted.
+func (a *Applier) deleteOwnComment(reader *bufio.Reader, suggestion *github.ReviewComment, own []ownComment) bool {
+	comment, ok := pickOwnComment(reader, own, "delete")
+	if !ok {
+		return false
+	}
+
+	fmt.Printf("\n%s ", ui.Colorize(ui.ColorYellow, fmt.Sprintf("Delete comment %d? [y/N]", comment.ID)))
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false
+	}
+	response = strings.ToLower(strings.TrimSpace(response))
+	if response != "y" && response != "yes" {
+		fmt.Printf("⏭️  Cancelled\n")
+		return false
+	}
+
+	if err := a.githubClient.DeleteReviewComment(comment.ID); err != nil {
+		fmt.Printf("❌ Failed to delete comment: %v\n", err)
+		return false
+	}
+	fmt.Printf("🗑️  Comment deleted\n")
+
+	if comment.Index < 0 {
+		return true
+	}
+	suggestion.ThreadComments = append(suggestion.ThreadComments[:comment.Index], suggestion.ThreadComments[comment.Index+1:]...)
+	return false
+}
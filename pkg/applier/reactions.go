@@ -0,0 +1,103 @@
+package applier
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+)
+
+// reactToComment adds a reaction to a comment of the thread of suggestion, or removes ours
+// when the reaction is prefixed with "-" (-1 being the 👎 reaction, not the removal of 1)
+func (a *Applier) reactToComment(reader *bufio.Reader, suggestion *github.ReviewComment) {
+	commentID, reactions := suggestion.ID, &suggestion.Reactions
+	if len(suggestion.ThreadComments) > 0 {
+		fmt.Printf("\n%s\n", ui.Colorize(ui.ColorCyan, "Comments in this thread:"))
+		fmt.Printf("  0) @%s %s\n", suggestion.Author, ui.Colorize(ui.ColorGray, commentPreview(suggestion.Body)))
+		for i, reply := range suggestion.ThreadComments {
+			fmt.Printf("  %d) @%s %s\n", i+1, reply.Author, ui.Colorize(ui.ColorGray, commentPreview(reply.Body)))
+		}
+		fmt.Printf("%s ", fmt.Sprintf("Which one to react to? [0-%d]", len(suggestion.ThreadComments)))
+
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		choice, err := strconv.Atoi(strings.TrimSpace(response))
+		if err != nil || choice < 0 || choice > len(suggestion.ThreadComments) {
+			fmt.Printf("⏭️  Cancelled\n")
+			return
+		}
+		if choice > 0 {
+			reply := &suggestion.ThreadComments[choice-1]
+			commentID, reactions = reply.ID, &reply.Reactions
+		}
+	}
+
+	fmt.Printf("%s ", fmt.Sprintf("Reaction (%s, prefix with - to remove):", strings.Join(github.ReactionContents, ", ")))
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return
+	}
+	response = strings.TrimSpace(response)
+	if response == "" || response == "-" {
+		fmt.Printf("⏭️  Cancelled\n")
+		return
+	}
+	content, err := github.ParseReaction(response)
+	remove := false
+	if err != nil {
+		name, ok := strings.CutPrefix(response, "-")
+		if !ok {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		if content, err = github.ParseReaction(name); err != nil {
+			fmt.Printf("❌ %v\n", err)
+			return
+		}
+		remove = true
+	}
+
+	if *reactions == nil {
+		*reactions = github.Reactions{}
+	}
+	if remove {
+		if err := a.githubClient.RemoveReaction(commentID, content); err != nil {
+			fmt.Printf("❌ Failed to remove reaction: %v\n", err)
+			return
+		}
+		if (*reactions)[content] > 0 {
+			(*reactions)[content]--
+		}
+		fmt.Printf("✅ Removed %s", github.ReactionEmoji(content))
+	} else {
+		created, err := a.githubClient.AddReaction(commentID, content)
+		if err != nil {
+			fmt.Printf("❌ Failed to react: %v\n", err)
+			return
+		}
+		if created {
+			(*reactions)[content]++
+			fmt.Printf("✅ Reacted with %s", github.ReactionEmoji(content))
+		} else {
+			fmt.Printf("Already reacted with %s", github.ReactionEmoji(content))
+		}
+	}
+	if counts := reactions.String(); counts != "" {
+		fmt.Printf(" (%s)", counts)
+	}
+	fmt.Println()
+}
+
+// commentPreview returns the first line of a comment body, shortened to fit a menu
+func commentPreview(body string) string {
+	preview := strings.SplitN(strings.TrimSpace(body), "\n", 2)[0]
+	if len(preview) > 60 {
+		preview = preview[:57] + "..."
+	}
+	return preview
+}
@@ -0,0 +1,277 @@
+package codecontext
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/diffhunk"
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// Line is a single line of code shown next to a review comment
+type Line struct {
+	Number int    // 1-based line number
+	Text   string // Line content without trailing newline
+	Marked bool   // Whether the line is part of the commented range
+}
+
+// Load reads a file and returns its lines without the trailing newline
+func Load(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return []string{}, nil
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// Excerpt returns the lines start..end (1-based, inclusive) of fileLines marked as commented,
+// surrounded by up to context lines on each side
+func Excerpt(fileLines []string, start, end, context int) ([]Line, error) {
+	if start <= 0 || end < start {
+		return nil, fmt.Errorf("invalid line range %d-%d", start, end)
+	}
+	if end > len(fileLines) {
+		return nil, fmt.Errorf("line range %d-%d is beyond the end of the file (%d lines)", start, end, len(fileLines))
+	}
+
+	from := max(start-context, 1)
+	to := min(end+context, len(fileLines))
+
+	lines := make([]Line, 0, to-from+1)
+	for n := from; n <= to; n++ {
+		lines = append(lines, Line{
+			Number: n,
+			Text:   fileLines[n-1],
+			Marked: n >= start && n <= end,
+		})
+	}
+	return lines, nil
+}
+
+// FromHunk rebuilds the lines start..end (1-based, inclusive) on the given side of a diff hunk,
+// surrounded by up to context lines available in the hunk. It is used as a fallback when the
+// local file is not available.
+func FromHunk(hunk string, start, end, context int, side diffposition.DiffSide) ([]Line, error) {
+	parsed, err := diffhunk.ParseDiffHunk(hunk)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Line
+	found := false
+	for _, line := range parsed.Lines {
+		number := line.NewLineNumber
+		skip := line.Type == diffhunk.Delete
+		if side == diffposition.DiffSideLeft {
+			number = line.OldLineNumber
+			skip = line.Type == diffhunk.Add
+		}
+		if skip || line.Type == diffhunk.Control || number < start-context || number > end+context {
+			continue
+		}
+		marked := number >= start && number <= end
+		found = found || marked
+		lines = append(lines, Line{Number: number, Text: line.Text, Marked: marked})
+	}
+
+	if !found {
+		return nil, fmt.Errorf("lines %d-%d not found in diff hunk", start, end)
+	}
+	return lines, nil
+}
+
+// Relocate finds where the expected lines now live in fileLines. It returns the 1-based line
+// of the exact match closest to hint, or false when the lines are not present in the file.
+func Relocate(fileLines, expected []string, hint int) (int, bool) {
+	if len(expected) == 0 || len(expected) > len(fileLines) {
+		return 0, false
+	}
+
+	best := 0
+	for i := 0; i <= len(fileLines)-len(expected); i++ {
+		match := true
+		for j, want := range expected {
+			if fileLines[i+j] != want {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if best == 0 || abs(i+1-hint) < abs(best-hint) {
+			best = i + 1
+		}
+	}
+
+	return best, best != 0
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// Snippet is the code a review comment refers to
+type Snippet struct {
+	Lines     []Line
+	Start     int  // First commented line in Lines
+	End       int  // Last commented line in Lines
+	FromHunk  bool // The lines come from the diff hunk rather than the local file
+	Relocated bool // The commented lines moved in the local file since the review
+}
+
+// Code returns the number of the first line of the snippet and the text of its lines, which
+// are consecutive
+func (s *Snippet) Code() (int, []string) {
+	if len(s.Lines) == 0 {
+		return s.Start, nil
+	}
+	texts := make([]string, 0, len(s.Lines))
+	for _, line := range s.Lines {
+		texts = append(texts, line.Text)
+	}
+	return s.Lines[0].Number, texts
+}
+
+// ForComment returns the code a review comment refers to, with up to context surrounding
+// lines. The local file is preferred: the commented lines are looked up by content so that
+// local edits above them do not shift the excerpt. When the file cannot be read or does not
+// contain the range, the lines are rebuilt from the comment's diff hunk. Comments on the base
+// side of the diff, or whose line no longer exists in the PR, always use the diff hunk.
+func ForComment(comment *github.ReviewComment, context int) (*Snippet, error) {
+	if comment.IsFileLevel() {
+		return nil, fmt.Errorf("file-level comments do not refer to specific lines")
+	}
+
+	// The diff hunk is numbered against the commit the comment was originally made on
+	hunkStart, hunkEnd := comment.OriginalStartLine, comment.OriginalEndLine
+	if hunkEnd <= 0 {
+		hunkStart, hunkEnd = comment.StartLine, comment.EndLine
+	}
+	if hunkStart <= 0 {
+		hunkStart = hunkEnd
+	}
+
+	fromHunk := func(cause error) (*Snippet, error) {
+		lines, err := FromHunk(comment.DiffHunk, hunkStart, hunkEnd, context, comment.DiffSide)
+		if err != nil {
+			if cause != nil {
+				return nil, fmt.Errorf("%w (diff hunk fallback: %v)", cause, err)
+			}
+			return nil, err
+		}
+		return &Snippet{Lines: lines, Start: hunkStart, End: hunkEnd, FromHunk: true}, nil
+	}
+
+	if comment.EndLine <= 0 || comment.DiffSide == diffposition.DiffSideLeft {
+		return fromHunk(nil)
+	}
+
+	fileLines, err := Load(comment.Path)
+	if err != nil {
+		return fromHunk(err)
+	}
+
+	start, end := comment.StartLine, comment.EndLine
+	if start <= 0 {
+		start = end
+	}
+
+	relocated := false
+	if expected, hunkErr := FromHunk(comment.DiffHunk, hunkStart, hunkEnd, 0, comment.DiffSide); hunkErr == nil {
+		texts := make([]string, 0, len(expected))
+		for _, line := range expected {
+			texts = append(texts, line.Text)
+		}
+		if found, ok := Relocate(fileLines, texts, start); ok && found != start {
+			end = found + len(texts) - 1
+			start = found
+			relocated = true
+		}
+	}
+
+	lines, err := Excerpt(fileLines, start, end, context)
+	if err != nil {
+		return fromHunk(err)
+	}
+	return &Snippet{Lines: lines, Start: start, End: end, Relocated: relocated}, nil
+}
+
+// SuggestionApplied reports whether the local file already contains the code suggested by a
+// comment where the suggestion applies. The commented range is relocated by content, with the
+// line above it in the diff hunk; once the commented lines are gone, that line alone anchors
+// the range. While the commented lines are still in place, the suggestion only counts as
+// applied when it keeps them, e.g. when it adds lines below them.
+func SuggestionApplied(comment *github.ReviewComment) (bool, error) {
+	if !comment.HasSuggestion || comment.IsFileLevel() || comment.DiffSide == diffposition.DiffSideLeft {
+		return false, nil
+	}
+	fileLines, err := Load(comment.Path)
+	if err != nil {
+		return false, err
+	}
+	suggested := strings.Split(strings.TrimSuffix(comment.SuggestedCode, "\n"), "\n")
+
+	hunkStart, hunkEnd := comment.OriginalStartLine, comment.OriginalEndLine
+	if hunkEnd <= 0 {
+		hunkStart, hunkEnd = comment.StartLine, comment.EndLine
+	}
+	if hunkStart <= 0 {
+		hunkStart = hunkEnd
+	}
+	start := comment.StartLine
+	if start <= 0 {
+		start = comment.EndLine
+	}
+
+	var commented []string
+	if lines, err := FromHunk(comment.DiffHunk, hunkStart, hunkEnd, 1, comment.DiffSide); err == nil {
+		var above []string
+		for _, line := range lines {
+			if line.Marked {
+				commented = append(commented, line.Text)
+			} else if line.Number < hunkStart {
+				above = append(above, line.Text)
+			}
+		}
+		// The line above is looked up with the commented lines, as these may be common
+		// enough to be found elsewhere in the file
+		if found, ok := Relocate(fileLines, append(above, commented...), start-len(above)); ok {
+			start = found + len(above)
+		} else if found, ok := Relocate(fileLines, above, start-1); ok {
+			start = found + 1
+		}
+	}
+
+	if len(commented) == 0 {
+		// Without the commented lines, an untouched file cannot be told apart
+		return false, nil
+	}
+	if linesAt(fileLines, start, commented) && !linesAt(suggested, 1, commented) {
+		return false, nil
+	}
+	return linesAt(fileLines, start, suggested), nil
+}
+
+// linesAt reports whether want is found in lines from the 1-based line start
+func linesAt(lines []string, start int, want []string) bool {
+	if start <= 0 || start+len(want)-1 > len(lines) {
+		return false
+	}
+	for i, line := range want {
+		if lines[start-1+i] != line {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,385 @@
+package codecontext
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "trailing newline",
+			content: "one\ntwo\n",
+			want:    []string{"one", "two"},
+		},
+		{
+			name:    "no trailing newline",
+			content: "one\ntwo",
+			want:    []string{"one", "two"},
+		},
+		{
+			name:    "empty file",
+			content: "",
+			want:    []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Load() on a missing file should fail")
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	fileLines := []string{"a", "b", "c", "d", "e", "f"}
+
+	tests := []struct {
+		name       string
+		start, end int
+		context    int
+		want       []Line
+		wantErr    bool
+	}{
+		{
+			name:  "single line without context",
+			start: 3, end: 3,
+			want: []Line{{Number: 3, Text: "c", Marked: true}},
+		},
+		{
+			name:  "multi-line range with context",
+			start: 3, end: 4, context: 1,
+			want: []Line{
+				{Number: 2, Text: "b"},
+				{Number: 3, Text: "c", Marked: true},
+				{Number: 4, Text: "d", Marked: true},
+				{Number: 5, Text: "e"},
+			},
+		},
+		{
+			name:  "context clamped to file bounds",
+			start: 1, end: 1, context: 2,
+			want: []Line{
+				{Number: 1, Text: "a", Marked: true},
+				{Number: 2, Text: "b"},
+				{Number: 3, Text: "c"},
+			},
+		},
+		{
+			name:  "range beyond end of file",
+			start: 6, end: 8,
+			wantErr: true,
+		},
+		{
+			name:  "inverted range",
+			start: 4, end: 2,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Excerpt(fileLines, tt.start, tt.end, tt.context)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Excerpt() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Excerpt() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromHunk(t *testing.T) {
+	hunk := `@@ -10,4 +10,4 @@ func example() {
+ context
+-old one
+-old two
++new one
++new two
+ trailing`
+
+	tests := []struct {
+		name       string
+		start, end int
+		context    int
+		side       diffposition.DiffSide
+		want       []Line
+		wantErr    bool
+	}{
+		{
+			name:  "right side range",
+			start: 11, end: 12,
+			side: diffposition.DiffSideRight,
+			want: []Line{
+				{Number: 11, Text: "new one", Marked: true},
+				{Number: 12, Text: "new two", Marked: true},
+			},
+		},
+		{
+			name:  "left side range",
+			start: 10, end: 11,
+			side: diffposition.DiffSideLeft,
+			want: []Line{
+				{Number: 10, Text: "context", Marked: true},
+				{Number: 11, Text: "old one", Marked: true},
+			},
+		},
+		{
+			name:  "right side range with context",
+			start: 11, end: 11, context: 1,
+			side: diffposition.DiffSideRight,
+			want: []Line{
+				{Number: 10, Text: "context"},
+				{Number: 11, Text: "new one", Marked: true},
+				{Number: 12, Text: "new two"},
+			},
+		},
+		{
+			name:  "range outside hunk",
+			start: 40, end: 41,
+			side:    diffposition.DiffSideRight,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FromHunk(hunk, tt.start, tt.end, tt.context, tt.side)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromHunk() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromHunk() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelocate(t *testing.T) {
+	fileLines := []string{"a", "x", "y", "b", "x", "y", "c"}
+
+	tests := []struct {
+		name     string
+		expected []string
+		hint     int
+		want     int
+		wantOK   bool
+	}{
+		{
+			name:     "match at hint",
+			expected: []string{"x", "y"},
+			hint:     2,
+			want:     2,
+			wantOK:   true,
+		},
+		{
+			name:     "closest match to hint wins",
+			expected: []string{"x", "y"},
+			hint:     6,
+			want:     5,
+			wantOK:   true,
+		},
+		{
+			name:     "lines no longer present",
+			expected: []string{"x", "z"},
+			hint:     2,
+			wantOK:   false,
+		},
+		{
+			name:     "nothing to look for",
+			expected: nil,
+			hint:     1,
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := Relocate(fileLines, tt.expected, tt.hint)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Relocate() = %d, %v, want %d, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestForComment(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	// Two lines were added locally above the commented code
+	content := "package main\n\n// added\n// added\nfunc main() {\n\tprintln(\"hi\")\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hunk := `@@ -1,3 +1,5 @@
+ package main
+ 
++func main() {
++	println("hi")
++}`
+
+	tests := []struct {
+		name    string
+		comment *github.ReviewComment
+		want    *Snippet
+		wantErr bool
+	}{
+		{
+			name: "relocated in local file",
+			comment: &github.ReviewComment{
+				Path: path, StartLine: 3, EndLine: 4, OriginalStartLine: 3, OriginalEndLine: 4,
+				DiffHunk: hunk, DiffSide: diffposition.DiffSideRight,
+			},
+			want: &Snippet{
+				Lines: []Line{
+					{Number: 4, Text: "// added"},
+					{Number: 5, Text: "func main() {", Marked: true},
+					{Number: 6, Text: "\tprintln(\"hi\")", Marked: true},
+					{Number: 7, Text: "}"},
+				},
+				Start: 5, End: 6, Relocated: true,
+			},
+		},
+		{
+			name: "missing file falls back to hunk",
+			comment: &github.ReviewComment{
+				Path: filepath.Join(dir, "missing.go"), StartLine: 3, EndLine: 3, OriginalStartLine: 3, OriginalEndLine: 3,
+				DiffHunk: hunk, DiffSide: diffposition.DiffSideRight,
+			},
+			want: &Snippet{
+				Lines: []Line{
+					{Number: 2, Text: ""},
+					{Number: 3, Text: "func main() {", Marked: true},
+					{Number: 4, Text: "\tprintln(\"hi\")"},
+				},
+				Start: 3, End: 3, FromHunk: true,
+			},
+		},
+		{
+			name: "file-level comment",
+			comment: &github.ReviewComment{
+				Path: path, SubjectType: "file",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ForComment(tt.comment, 1)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ForComment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ForComment() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestionApplied(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	// The suggestion was applied, and two lines were added above it since
+	content := "package main\n\n// added\n// added\nfunc main() {\n\tprintln(\"bye\")\n}\n\nfunc other() {\n\tprintln(\"hi\")\n}\n"
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	hunk := `@@ -1,3 +1,5 @@
+ package main
+ 
++func main() {
++	println("hi")
++}`
+
+	comment := func(suggestion string) *github.ReviewComment {
+		return &github.ReviewComment{
+			Path: path, StartLine: 4, EndLine: 4, OriginalStartLine: 4, OriginalEndLine: 4,
+			DiffHunk: hunk, DiffSide: diffposition.DiffSideRight,
+			HasSuggestion: suggestion != "", SuggestedCode: suggestion,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		comment *github.ReviewComment
+		want    bool
+		wantErr bool
+	}{
+		{
+			name:    "applied at the relocated range",
+			comment: comment("\tprintln(\"bye\")\n"),
+			want:    true,
+		},
+		{
+			name:    "commented line still there",
+			comment: comment("\tprintln(\"hello\")\n"),
+		},
+		{
+			name:    "commented line kept with a line added below",
+			comment: comment("\tprintln(\"bye\")\n}\n"),
+			want:    true,
+		},
+		{
+			name:    "no suggestion",
+			comment: comment(""),
+		},
+		{
+			name: "suggestion removing lines not applied yet",
+			comment: &github.ReviewComment{
+				Path: path, StartLine: 9, EndLine: 10, OriginalStartLine: 9, OriginalEndLine: 10,
+				DiffHunk: `@@ -8,0 +9,3 @@
++func other() {
++	println("hi")
++}`,
+				DiffSide: diffposition.DiffSideRight, HasSuggestion: true, SuggestedCode: "func other() {\n",
+			},
+		},
+		{
+			name: "missing file",
+			comment: &github.ReviewComment{
+				Path: filepath.Join(dir, "missing.go"), StartLine: 1, EndLine: 1,
+				HasSuggestion: true, SuggestedCode: "x",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SuggestionApplied(tt.comment)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SuggestionApplied() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SuggestionApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Package deferred keeps the review threads addressed locally until their fixes are pushed,
+// so that they are only resolved once reviewers can see the change.
+package deferred
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// queueFile is the queue of a checkout, under its git directory
+const queueFile = "gh-prreview/deferred-resolve.json"
+
+// Thread is a review thread to resolve once its fix is pushed
+type Thread struct {
+	Host        string    `json:"host"` // github.com included
+	Repo        string    `json:"repo"` // OWNER/REPO
+	PullRequest int       `json:"pull_request"`
+	ThreadID    string    `json:"thread_id"`
+	CommentID   int64     `json:"comment_id"`
+	Path        string    `json:"path"`
+	Location    string    `json:"location"`
+	BaseSHA     string    `json:"base_sha"` // HEAD when the fix was applied
+	AddedAt     time.Time `json:"added_at"`
+}
+
+// HostRepo returns HOST/OWNER/REPO. Threads queued before the host was recorded kept it in
+// Repo, except on github.com.
+func (t Thread) HostRepo() string {
+	switch {
+	case t.Host != "":
+		return t.Host + "/" + t.Repo
+	case strings.Count(t.Repo, "/") == 2:
+		return t.Repo
+	}
+	return "github.com/" + t.Repo
+}
+
+// Queue is the list of threads waiting for a push, stored in a JSON file
+type Queue struct {
+	path    string
+	Threads []Thread
+}
+
+// QueuePath returns where the queue of the current checkout is stored
+func QueuePath() (string, error) {
+	path, err := git("rev-parse", "--git-path", queueFile)
+	if err != nil {
+		return "", fmt.Errorf("not in a git checkout: %w", err)
+	}
+	return filepath.Abs(path)
+}
+
+// Load reads the queue stored at path; a missing file is an empty queue
+func Load(path string) (*Queue, error) {
+	queue := &Queue{path: path}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return queue, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &queue.Threads); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return queue, nil
+}
+
+// Add queues a thread, replacing an earlier entry for the same thread
+func (q *Queue) Add(thread Thread) {
+	if thread.AddedAt.IsZero() {
+		thread.AddedAt = time.Now()
+	}
+	q.Remove(thread.ThreadID)
+	q.Threads = append(q.Threads, thread)
+}
+
+// Remove drops a thread from the queue
+func (q *Queue) Remove(threadID string) {
+	kept := q.Threads[:0]
+	for _, thread := range q.Threads {
+		if thread.ThreadID != threadID {
+			kept = append(kept, thread)
+		}
+	}
+	q.Threads = kept
+}
+
+// Save writes the queue back, removing the file once the queue is empty
+func (q *Queue) Save() error {
+	if len(q.Threads) == 0 {
+		if err := os.Remove(q.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	data, err := json.MarshalIndent(q.Threads, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(q.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(q.path, append(data, '\n'), 0o644)
+}
+
+// FixCommit returns the latest commit since the thread was queued that changes its file, or
+// "" when the fix is not committed yet
+func FixCommit(thread Thread) (string, error) {
+	args := []string{"log", "-1", "--format=%H", "HEAD", "--", thread.Path}
+	if thread.BaseSHA != "" {
+		args[3] = thread.BaseSHA + "..HEAD"
+	}
+	return git(args...)
+}
+
+// IsPushed reports whether commit is part of the pushed head of the pull request. The head
+// is only known locally once it was pushed or fetched.
+func IsPushed(commit, head string) bool {
+	if commit == "" || head == "" {
+		return false
+	}
+	_, err := git("merge-base", "--is-ancestor", commit, head)
+	return err == nil
+}
+
+// git runs a git command and returns its trimmed output
+func git(args ...string) (string, error) {
+	output, err := exec.Command("git", args...).Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+			return "", fmt.Errorf("git %s: %s", args[0], strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
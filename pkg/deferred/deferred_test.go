@@ -0,0 +1,163 @@
+package deferred
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gh-prreview", "deferred-resolve.json")
+	added := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	queue, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing file error = %v", err)
+	}
+	queue.Add(Thread{Repo: "owner/repo", PullRequest: 42, ThreadID: "PRRT_1", CommentID: 1, BaseSHA: "aaa", AddedAt: added})
+	queue.Add(Thread{Repo: "owner/repo", PullRequest: 42, ThreadID: "PRRT_2", CommentID: 2, AddedAt: added})
+	// Queuing a thread again replaces it
+	queue.Add(Thread{Repo: "owner/repo", PullRequest: 42, ThreadID: "PRRT_1", CommentID: 1, BaseSHA: "bbb", AddedAt: added})
+	if err := queue.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := []Thread{
+		{Repo: "owner/repo", PullRequest: 42, ThreadID: "PRRT_2", CommentID: 2, AddedAt: added},
+		{Repo: "owner/repo", PullRequest: 42, ThreadID: "PRRT_1", CommentID: 1, BaseSHA: "bbb", AddedAt: added},
+	}
+	if !reflect.DeepEqual(loaded.Threads, want) {
+		t.Errorf("Threads = %+v, want %+v", loaded.Threads, want)
+	}
+
+	loaded.Remove("PRRT_1")
+	loaded.Remove("PRRT_2")
+	if err := loaded.Save(); err != nil {
+		t.Fatalf("Save() of an empty queue error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the file of an empty queue should be removed, got %v", err)
+	}
+}
+
+func TestThreadHostRepo(t *testing.T) {
+	tests := []struct {
+		thread Thread
+		want   string
+	}{
+		{Thread{Host: "github.com", Repo: "owner/repo"}, "github.com/owner/repo"},
+		{Thread{Host: "ghe.example.com", Repo: "owner/repo"}, "ghe.example.com/owner/repo"},
+		// Queued before the host was recorded
+		{Thread{Repo: "ghe.example.com/owner/repo"}, "ghe.example.com/owner/repo"},
+		{Thread{Repo: "owner/repo"}, "github.com/owner/repo"},
+	}
+	for _, tt := range tests {
+		if got := tt.thread.HostRepo(); got != tt.want {
+			t.Errorf("HostRepo() of %+v = %q, want %q", tt.thread, got, tt.want)
+		}
+	}
+}
+
+func TestFixCommit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "-q")
+	write("main.go", "package main\n")
+	write("other.go", "package main\n")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	base := run("rev-parse", "HEAD")
+	thread := Thread{ThreadID: "PRRT_1", Path: "main.go", BaseSHA: base}
+
+	// Not committed yet
+	write("main.go", "package main\n\nfunc main() {}\n")
+	if got, err := FixCommit(thread); err != nil || got != "" {
+		t.Fatalf("FixCommit() before the commit = %q, %v, want none", got, err)
+	}
+
+	run("commit", "-q", "-am", "fix")
+	fix := run("rev-parse", "HEAD")
+	write("other.go", "package main\n\n// unrelated\n")
+	run("commit", "-q", "-am", "unrelated")
+
+	got, err := FixCommit(thread)
+	if err != nil || got != fix {
+		t.Fatalf("FixCommit() = %q, %v, want %q", got, err, fix)
+	}
+	if !IsPushed(fix, run("rev-parse", "HEAD")) {
+		t.Errorf("IsPushed() = false for an ancestor of the head")
+	}
+	if IsPushed(fix, base) {
+		t.Errorf("IsPushed() = true for a head without the fix")
+	}
+	if IsPushed(fix, "0123456789abcdef0123456789abcdef01234567") {
+		t.Errorf("IsPushed() = true for a head that is not available locally")
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hooks", "reference-transaction")
+	command := "gh prreview finish --hook"
+
+	if err := InstallHook(path, command); err != nil {
+		t.Fatalf("InstallHook() error = %v", err)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != HookScript(command) || !strings.Contains(string(content), command+" || true") {
+		t.Errorf("hook = %q", content)
+	}
+	// Installing again replaces our own hook
+	if err := InstallHook(path, command+" --reply"); err != nil {
+		t.Fatalf("InstallHook() over our hook error = %v", err)
+	}
+	if err := UninstallHook(path); err != nil {
+		t.Fatalf("UninstallHook() error = %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("hook should be removed, got %v", err)
+	}
+
+	// Someone else's hook is left alone
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho mine\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := InstallHook(path, command); err == nil {
+		t.Errorf("InstallHook() over another hook should fail")
+	}
+	if err := UninstallHook(path); err == nil {
+		t.Errorf("UninstallHook() of another hook should fail")
+	}
+}
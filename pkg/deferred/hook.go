@@ -0,0 +1,60 @@
+package deferred
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hookMarker identifies the hook written by InstallHook
+const hookMarker = "# Installed by gh prreview finish --install-hook"
+
+// HookScript returns a reference-transaction hook running command after a push. Git has no
+// post-push hook, but a successful push updates the remote-tracking refs in a committed
+// reference transaction; fetches do too, which command must tolerate.
+func HookScript(command string) string {
+	return fmt.Sprintf(`#!/bin/sh
+%s
+[ "$1" = committed ] || exit 0
+grep ' refs/remotes/' >/dev/null || exit 0
+%s || true
+`, hookMarker, command)
+}
+
+// HookPath returns where git looks for the reference-transaction hook of the checkout
+func HookPath() (string, error) {
+	path, err := git("rev-parse", "--git-path", "hooks/reference-transaction")
+	if err != nil {
+		return "", fmt.Errorf("not in a git checkout: %w", err)
+	}
+	return filepath.Abs(path)
+}
+
+// InstallHook writes the hook running command at path. A hook that was not installed by us
+// is left alone.
+func InstallHook(path, command string) error {
+	if existing, err := os.ReadFile(path); err == nil && !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s already exists and was not installed by gh prreview; add this line to it instead: %s", path, command)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(HookScript(command)), 0o755)
+}
+
+// UninstallHook removes the hook at path if we installed it
+func UninstallHook(path string) error {
+	existing, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(existing), hookMarker) {
+		return fmt.Errorf("%s was not installed by gh prreview, remove it by hand", path)
+	}
+	return os.Remove(path)
+}
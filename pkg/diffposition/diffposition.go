@@ -223,3 +223,14 @@ func GetCommentingRanges(patch string, isBase bool) ([][2]int, error) {
 
 	return ranges, nil
 }
+
+// IsRangeCommentable tells whether the lines start to end all fall within one of the ranges
+// returned by GetCommentingRanges. A multi-line comment cannot span lines outside the diff.
+func IsRangeCommentable(ranges [][2]int, start, end int) bool {
+	for _, r := range ranges {
+		if start >= r[0] && end <= r[1] {
+			return true
+		}
+	}
+	return false
+}
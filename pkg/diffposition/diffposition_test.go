@@ -429,6 +429,31 @@ func TestGetCommentingRanges(t *testing.T) {
 	}
 }
 
+func TestIsRangeCommentable(t *testing.T) {
+	ranges := [][2]int{{10, 14}, {20, 22}}
+
+	tests := []struct {
+		name       string
+		start, end int
+		want       bool
+	}{
+		{name: "single line", start: 12, end: 12, want: true},
+		{name: "whole range", start: 10, end: 14, want: true},
+		{name: "second range", start: 21, end: 22, want: true},
+		{name: "outside", start: 16, end: 16, want: false},
+		{name: "overflowing a range", start: 13, end: 15, want: false},
+		{name: "spanning two ranges", start: 12, end: 21, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRangeCommentable(ranges, tt.start, tt.end); got != tt.want {
+				t.Errorf("IsRangeCommentable(%d, %d) = %v, want %v", tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestDiffSide(t *testing.T) {
 	tests := []struct {
 		side DiffSide
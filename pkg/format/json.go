@@ -0,0 +1,250 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// SchemaVersion is the version of the JSON documents produced by this package. It is bumped
+// whenever a field is renamed, removed or changes meaning; new fields may be added without
+// a version change.
+const SchemaVersion = 1
+
+// Document is the structured representation of a pull request's review feedback
+type Document struct {
+	SchemaVersion int            `json:"schema_version"`
+	Repository    string         `json:"repository"`
+	RepositoryURL string         `json:"repository_url,omitempty"`
+	PullRequest   int            `json:"pull_request"`
+	Reviews       []Review       `json:"reviews,omitempty"`
+	IssueComments []IssueComment `json:"issue_comments,omitempty"`
+	Comments      []Comment      `json:"comments"`
+}
+
+// Comment is a review thread, identified by its first comment
+type Comment struct {
+	ID                int64       `json:"id"`
+	ThreadID          string      `json:"thread_id,omitempty"`
+	ReviewID          int64       `json:"review_id,omitempty"`
+	Path              string      `json:"path"`
+	Scope             string      `json:"scope"` // "line" or "file"
+	Side              string      `json:"side,omitempty"`
+	Line              int         `json:"line,omitempty"`
+	StartLine         int         `json:"start_line,omitempty"`
+	EndLine           int         `json:"end_line,omitempty"`
+	OriginalStartLine int         `json:"original_start_line,omitempty"`
+	OriginalEndLine   int         `json:"original_end_line,omitempty"`
+	Local             *LocalRange `json:"local,omitempty"`
+	Author            string      `json:"author"`
+	Body              string      `json:"body"`
+	URL               string      `json:"url"`
+	CreatedAt         time.Time   `json:"created_at"`
+	Resolved          bool        `json:"resolved"`
+	Outdated          bool        `json:"outdated"`
+	Suggestion        *Suggestion `json:"suggestion,omitempty"`
+	DiffHunk          string      `json:"diff_hunk,omitempty"`
+	Replies           []Reply     `json:"replies"`
+}
+
+// LocalRange is where the commented lines are in the local checkout
+type LocalRange struct {
+	StartLine int  `json:"start_line"`
+	EndLine   int  `json:"end_line"`
+	Relocated bool `json:"relocated"` // The lines moved since the review
+}
+
+// Suggestion is a GitHub suggested change parsed from a comment body
+type Suggestion struct {
+	Code string `json:"code"`
+}
+
+// Reply is a follow-up comment in a review thread
+type Reply struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Review is a submitted pull request review
+type Review struct {
+	ID          int64     `json:"id"`
+	Author      string    `json:"author"`
+	State       string    `json:"state"`
+	Body        string    `json:"body"`
+	URL         string    `json:"url"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// IssueComment is a general comment in the PR conversation
+type IssueComment struct {
+	ID        int64     `json:"id"`
+	Author    string    `json:"author"`
+	Body      string    `json:"body"`
+	URL       string    `json:"url"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NewDocument builds a document from the fetched review data. Reviews and issue comments are
+// optional and omitted from the output when nil.
+func NewDocument(repo string, prNumber int, comments []*github.ReviewComment, reviews []*github.Review, issueComments []*github.IssueComment) *Document {
+	doc := &Document{
+		SchemaVersion: SchemaVersion,
+		Repository:    repo,
+		PullRequest:   prNumber,
+		Comments:      make([]Comment, 0, len(comments)),
+	}
+
+	for _, comment := range comments {
+		doc.Comments = append(doc.Comments, NewComment(comment))
+	}
+
+	if reviews != nil {
+		doc.Reviews = make([]Review, 0, len(reviews))
+		for _, review := range reviews {
+			doc.Reviews = append(doc.Reviews, Review{
+				ID:          review.ID,
+				Author:      review.Author,
+				State:       review.State,
+				Body:        review.Body,
+				URL:         review.HTMLURL,
+				SubmittedAt: review.SubmittedAt,
+			})
+		}
+	}
+
+	if issueComments != nil {
+		doc.IssueComments = make([]IssueComment, 0, len(issueComments))
+		for _, comment := range issueComments {
+			doc.IssueComments = append(doc.IssueComments, IssueComment{
+				ID:        comment.ID,
+				Author:    comment.Author,
+				Body:      comment.Body,
+				URL:       comment.HTMLURL,
+				CreatedAt: comment.CreatedAt,
+			})
+		}
+	}
+
+	return doc
+}
+
+// NewComment converts a review comment, resolving where its lines are in the local checkout
+func NewComment(comment *github.ReviewComment) Comment {
+	c := Comment{
+		ID:        comment.ID,
+		ThreadID:  comment.ThreadID,
+		ReviewID:  comment.ReviewID,
+		Path:      comment.Path,
+		Scope:     "line",
+		Author:    comment.Author,
+		Body:      comment.Body,
+		URL:       comment.HTMLURL,
+		CreatedAt: comment.CreatedAt,
+		Resolved:  comment.IsResolved(),
+		Outdated:  comment.IsOutdated,
+		DiffHunk:  comment.DiffHunk,
+		Replies:   make([]Reply, 0, len(comment.ThreadComments)),
+	}
+
+	if comment.IsFileLevel() {
+		c.Scope = "file"
+	} else {
+		c.Side = string(comment.DiffSide)
+		c.Line = comment.Line
+		c.StartLine = comment.StartLine
+		c.EndLine = comment.EndLine
+		c.OriginalStartLine = comment.OriginalStartLine
+		c.OriginalEndLine = comment.OriginalEndLine
+
+		if snippet, err := codecontext.ForComment(comment, 0); err == nil && !snippet.FromHunk {
+			c.Local = &LocalRange{StartLine: snippet.Start, EndLine: snippet.End, Relocated: snippet.Relocated}
+		}
+	}
+
+	if comment.HasSuggestion {
+		c.Suggestion = &Suggestion{Code: comment.SuggestedCode}
+	}
+
+	for _, reply := range comment.ThreadComments {
+		c.Replies = append(c.Replies, Reply{
+			ID:        reply.ID,
+			Author:    reply.Author,
+			Body:      reply.Body,
+			URL:       reply.HTMLURL,
+			CreatedAt: reply.CreatedAt,
+		})
+	}
+
+	return c
+}
+
+// WriteJSON writes the document as indented JSON
+func WriteJSON(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+// recordHeader identifies each NDJSON line
+type recordHeader struct {
+	SchemaVersion int    `json:"schema_version"`
+	Type          string `json:"type"` // "review", "issue_comment" or "comment"
+	Repository    string `json:"repository"`
+	PullRequest   int    `json:"pull_request"`
+}
+
+// WriteNDJSON writes the document as newline-delimited JSON: one self-describing record per
+// review, issue comment and review thread, in that order
+func WriteNDJSON(w io.Writer, doc *Document) error {
+	encoder := json.NewEncoder(w)
+	header := func(recordType string) recordHeader {
+		return recordHeader{
+			SchemaVersion: doc.SchemaVersion,
+			Type:          recordType,
+			Repository:    doc.Repository,
+			PullRequest:   doc.PullRequest,
+		}
+	}
+
+	for i := range doc.Reviews {
+		record := struct {
+			recordHeader
+			*Review
+		}{header("review"), &doc.Reviews[i]}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode review: %w", err)
+		}
+	}
+
+	for i := range doc.IssueComments {
+		record := struct {
+			recordHeader
+			*IssueComment
+		}{header("issue_comment"), &doc.IssueComments[i]}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode issue comment: %w", err)
+		}
+	}
+
+	for i := range doc.Comments {
+		record := struct {
+			recordHeader
+			*Comment
+		}{header("comment"), &doc.Comments[i]}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode comment: %w", err)
+		}
+	}
+
+	return nil
+}
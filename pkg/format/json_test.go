@@ -0,0 +1,134 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func testComments() []*github.ReviewComment {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	return []*github.ReviewComment{
+		{
+			ID:                1,
+			ThreadID:          "PRRT_1",
+			ReviewID:          10,
+			Path:              "does/not/exist.go",
+			Line:              12,
+			StartLine:         10,
+			EndLine:           12,
+			OriginalStartLine: 10,
+			OriginalEndLine:   12,
+			DiffSide:          diffposition.DiffSideRight,
+			SubjectType:       "line",
+			Author:            "alice",
+			Body:              "use this\n```suggestion\nreturn nil\n```",
+			HasSuggestion:     true,
+			SuggestedCode:     "return nil",
+			HTMLURL:           "https://github.com/o/r/pull/1#discussion_r1",
+			CreatedAt:         created,
+			ThreadComments: []github.ThreadComment{
+				{ID: 2, Author: "bob", Body: "done", HTMLURL: "https://github.com/o/r/pull/1#discussion_r2", CreatedAt: created},
+			},
+		},
+		{
+			ID:          3,
+			Path:        "README.md",
+			SubjectType: "file",
+			Author:      "carol",
+			Body:        "needs a usage section",
+			Resolved:    true,
+			CreatedAt:   created,
+		},
+	}
+}
+
+func TestNewComment(t *testing.T) {
+	comments := testComments()
+
+	line := NewComment(comments[0])
+	if line.Scope != "line" || line.StartLine != 10 || line.EndLine != 12 || line.Side != "RIGHT" {
+		t.Errorf("unexpected line comment position: %+v", line)
+	}
+	if line.Suggestion == nil || line.Suggestion.Code != "return nil" {
+		t.Errorf("expected suggestion to be parsed, got %+v", line.Suggestion)
+	}
+	if len(line.Replies) != 1 || line.Replies[0].Author != "bob" {
+		t.Errorf("expected one reply from bob, got %+v", line.Replies)
+	}
+	if line.Local != nil {
+		t.Errorf("expected no local range for a missing file, got %+v", line.Local)
+	}
+
+	file := NewComment(comments[1])
+	if file.Scope != "file" || file.Line != 0 || file.Side != "" {
+		t.Errorf("unexpected file comment position: %+v", file)
+	}
+	if !file.Resolved {
+		t.Error("expected file comment to be resolved")
+	}
+	if file.Replies == nil {
+		t.Error("replies should encode as an empty list, not null")
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	doc := NewDocument("o/r", 1, testComments(), nil, nil)
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, doc); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var decoded map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if decoded["schema_version"] != float64(SchemaVersion) {
+		t.Errorf("schema_version = %v, want %d", decoded["schema_version"], SchemaVersion)
+	}
+	if _, ok := decoded["reviews"]; ok {
+		t.Error("reviews should be omitted when not requested")
+	}
+	if comments, ok := decoded["comments"].([]any); !ok || len(comments) != 2 {
+		t.Errorf("expected 2 comments, got %v", decoded["comments"])
+	}
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	reviews := []*github.Review{{ID: 10, Author: "alice", State: "CHANGES_REQUESTED"}}
+	issueComments := []*github.IssueComment{{ID: 20, Author: "dave", Body: "ping"}}
+	doc := NewDocument("o/r", 1, testComments(), reviews, issueComments)
+
+	var buf bytes.Buffer
+	if err := WriteNDJSON(&buf, doc); err != nil {
+		t.Fatalf("WriteNDJSON() error = %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	wantTypes := []string{"review", "issue_comment", "comment", "comment"}
+	if len(lines) != len(wantTypes) {
+		t.Fatalf("got %d records, want %d:\n%s", len(lines), len(wantTypes), buf.String())
+	}
+
+	for i, line := range lines {
+		var record map[string]any
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("record %d is not valid JSON: %v", i, err)
+		}
+		if record["type"] != wantTypes[i] {
+			t.Errorf("record %d type = %v, want %s", i, record["type"], wantTypes[i])
+		}
+		if record["schema_version"] != float64(SchemaVersion) || record["repository"] != "o/r" {
+			t.Errorf("record %d is missing its header: %v", i, record)
+		}
+		if _, ok := record["id"]; !ok {
+			t.Errorf("record %d should be flattened, got %v", i, record)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+)
+
+// LLMSeparator separates the blocks of the LLM output
+const LLMSeparator = "---\n"
+
+// LLMOptions controls what the LLM output includes for each review comment
+type LLMOptions struct {
+	// DiffHunk adds the GitHub diff hunk of each comment
+	DiffHunk bool
+	// CurrentCode adds an excerpt of the local file around each comment
+	CurrentCode  bool
+	ContextLines int
+	// MaxTokens caps the estimated size of the output; 0 means no limit
+	MaxTokens int
+}
+
+// LLMOutput is the rendered LLM block of each review comment that fits in the token budget
+type LLMOutput struct {
+	blocks    map[int64]string
+	limited   bool
+	remaining int
+	// Omitted is the number of comments, reviews and PR comments dropped to fit the budget
+	Omitted int
+	// Shortened is the number of comments rendered with truncated replies or without code
+	Shortened int
+}
+
+// Block returns the rendered block for a comment, or false when it did not fit the budget
+func (o *LLMOutput) Block(comment *github.ReviewComment) (string, bool) {
+	block, ok := o.blocks[comment.ID]
+	return block, ok
+}
+
+// Charge takes another block of the output, such as a review or a PR comment, from what the
+// review comments left of the token budget. A block that does not fit is counted as omitted
+// and false is returned.
+func (o *LLMOutput) Charge(block string) bool {
+	if !o.limited {
+		return true
+	}
+	cost := EstimateTokens(block + LLMSeparator)
+	if cost > o.remaining {
+		o.Omitted++
+		return false
+	}
+	o.remaining -= cost
+	return true
+}
+
+// llmDetail is how much of a comment is rendered; budget pressure moves to later levels
+type llmDetail struct {
+	maxReplyRunes int // 0 keeps replies whole
+	diffHunk      bool
+	currentCode   bool
+}
+
+const (
+	longReplyRunes  = 500
+	shortReplyRunes = 100
+)
+
+// RenderLLM renders review comments for LLM consumption. With a token budget, comments are
+// considered by priority (unresolved before resolved, current before outdated, then in their
+// original order); each is rendered with as much detail as still fits: long replies are
+// truncated first, then the diff hunk and the local code are dropped. Comments that do not
+// fit even then are omitted. What is left of the budget goes to the blocks passed to Charge.
+func RenderLLM(comments []*github.ReviewComment, opts LLMOptions) *LLMOutput {
+	output := &LLMOutput{blocks: make(map[int64]string, len(comments))}
+
+	full := llmDetail{diffHunk: opts.DiffHunk, currentCode: opts.CurrentCode}
+	if opts.MaxTokens <= 0 {
+		for _, comment := range comments {
+			output.blocks[comment.ID] = renderLLMComment(comment, opts, full)
+		}
+		return output
+	}
+
+	levels := []llmDetail{
+		full,
+		{maxReplyRunes: longReplyRunes, diffHunk: opts.DiffHunk, currentCode: opts.CurrentCode},
+		{maxReplyRunes: longReplyRunes, currentCode: opts.CurrentCode},
+		{maxReplyRunes: longReplyRunes},
+		{maxReplyRunes: shortReplyRunes},
+	}
+
+	output.limited = true
+	output.remaining = opts.MaxTokens
+	for _, comment := range llmPriorityOrder(comments) {
+		fullBlock := renderLLMComment(comment, opts, full)
+		kept := false
+		for _, level := range levels {
+			block := fullBlock
+			if level != full {
+				block = renderLLMComment(comment, opts, level)
+			}
+			cost := EstimateTokens(block + LLMSeparator)
+			if cost > output.remaining {
+				continue
+			}
+			output.blocks[comment.ID] = block
+			output.remaining -= cost
+			if block != fullBlock {
+				output.Shortened++
+			}
+			kept = true
+			break
+		}
+		if !kept {
+			output.Omitted++
+		}
+	}
+	return output
+}
+
+// EstimateTokens approximates the number of tokens of text, at about four characters a token
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// llmPriorityOrder returns the comments most worth keeping first
+func llmPriorityOrder(comments []*github.ReviewComment) []*github.ReviewComment {
+	rank := func(comment *github.ReviewComment) int {
+		r := 0
+		if comment.IsResolved() {
+			r += 2
+		}
+		if comment.IsOutdated {
+			r++
+		}
+		return r
+	}
+
+	ordered := make([]*github.ReviewComment, len(comments))
+	copy(ordered, comments)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return rank(ordered[i]) < rank(ordered[j])
+	})
+	return ordered
+}
+
+func renderLLMComment(comment *github.ReviewComment, opts LLMOptions, detail llmDetail) string {
+	var b strings.Builder
+
+	if comment.IsFileLevel() {
+		fmt.Fprintf(&b, "FILE: %s\n", comment.Path)
+		b.WriteString("SCOPE: file\n")
+	} else {
+		fmt.Fprintf(&b, "FILE: %s:%d\n", comment.Path, comment.Line)
+		if start, end := llmLineRange(comment); start > 0 {
+			fmt.Fprintf(&b, "LINES: %d-%d\n", start, end)
+		}
+		if comment.DiffSide != "" {
+			fmt.Fprintf(&b, "SIDE: %s\n", comment.DiffSide)
+		}
+	}
+	fmt.Fprintf(&b, "COMMENT_ID: %d\n", comment.ID)
+	if comment.ThreadID != "" {
+		fmt.Fprintf(&b, "THREAD_ID: %s\n", comment.ThreadID)
+	}
+	fmt.Fprintf(&b, "AUTHOR: %s\n", comment.Author)
+	fmt.Fprintf(&b, "URL: %s\n", comment.HTMLURL)
+
+	status := "unresolved"
+	if comment.IsResolved() {
+		status = "resolved"
+	}
+	if comment.IsOutdated {
+		status += ", outdated"
+	}
+	fmt.Fprintf(&b, "STATUS: %s\n", status)
+
+	// Show the review comment (without suggestion block)
+	if commentText := ui.StripSuggestionBlock(comment.Body); commentText != "" {
+		fmt.Fprintf(&b, "COMMENT:\n%s\n", commentText)
+	}
+
+	if comment.HasSuggestion {
+		fmt.Fprintf(&b, "SUGGESTION:\n%s\n", comment.SuggestedCode)
+	}
+
+	if detail.currentCode && !comment.IsFileLevel() {
+		if snippet, err := codecontext.ForComment(comment, opts.ContextLines); err == nil {
+			if snippet.FromHunk {
+				b.WriteString("CURRENT_CODE (from diff hunk, local file not available):\n")
+			} else {
+				fmt.Fprintf(&b, "CURRENT_CODE (local lines %d-%d, marked with >):\n", snippet.Start, snippet.End)
+			}
+			b.WriteString(codeText(snippet.Lines) + "\n")
+		}
+	}
+
+	if detail.diffHunk && comment.DiffHunk != "" {
+		fmt.Fprintf(&b, "DIFF_HUNK:\n%s\n", strings.TrimRight(comment.DiffHunk, "\n"))
+	}
+
+	if len(comment.ThreadComments) > 0 {
+		b.WriteString("REPLIES:\n")
+		for i, reply := range comment.ThreadComments {
+			fmt.Fprintf(&b, "  [%d] %s: %s\n", i+1, reply.Author, truncateRunes(reply.Body, detail.maxReplyRunes))
+		}
+	}
+
+	return b.String()
+}
+
+// llmLineRange is the range of lines a comment covers on its side of the diff
+func llmLineRange(comment *github.ReviewComment) (int, int) {
+	start, end := comment.StartLine, comment.EndLine
+	if end == 0 {
+		end = comment.Line
+	}
+	if start == 0 {
+		start = end
+	}
+	return start, end
+}
+
+// truncateRunes shortens text to at most limit runes, saying how much was cut. A limit of
+// 0 keeps the text whole.
+func truncateRunes(text string, limit int) string {
+	runes := []rune(text)
+	if limit <= 0 || len(runes) <= limit {
+		return text
+	}
+	return fmt.Sprintf("%s… [truncated %d chars]", string(runes[:limit]), len(runes)-limit)
+}
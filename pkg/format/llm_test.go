@@ -0,0 +1,127 @@
+package format
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		limit int
+		want  string
+	}{
+		{name: "no limit", text: "hello", limit: 0, want: "hello"},
+		{name: "short enough", text: "hello", limit: 5, want: "hello"},
+		{name: "truncated", text: "hello world", limit: 5, want: "hello… [truncated 6 chars]"},
+		{name: "multi-byte runes", text: "héllo", limit: 2, want: "hé… [truncated 3 chars]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := truncateRunes(tt.text, tt.limit); got != tt.want {
+				t.Errorf("truncateRunes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderLLM(t *testing.T) {
+	comments := testComments()
+	comments[0].DiffHunk = "@@ -10,3 +10,3 @@\n+a\n+b\n+c"
+
+	output := RenderLLM(comments, LLMOptions{DiffHunk: true})
+	block, ok := output.Block(comments[0])
+	if !ok {
+		t.Fatal("comment should be rendered without a budget")
+	}
+	for _, want := range []string{
+		"FILE: does/not/exist.go:12\n",
+		"LINES: 10-12\n",
+		"THREAD_ID: PRRT_1\n",
+		"STATUS: unresolved\n",
+		"SUGGESTION:\nreturn nil\n",
+		"DIFF_HUNK:\n@@ -10,3 +10,3 @@",
+		"  [1] bob: done\n",
+	} {
+		if !strings.Contains(block, want) {
+			t.Errorf("block is missing %q:\n%s", want, block)
+		}
+	}
+
+	file, _ := output.Block(comments[1])
+	if !strings.Contains(file, "SCOPE: file\n") || !strings.Contains(file, "STATUS: resolved\n") {
+		t.Errorf("unexpected file-level block:\n%s", file)
+	}
+}
+
+func TestRenderLLMBudget(t *testing.T) {
+	long := strings.Repeat("x", 2000)
+	comments := []*github.ReviewComment{
+		{ID: 1, Path: "a.go", Line: 1, Author: "a", Body: "resolved one", Resolved: true},
+		{ID: 2, Path: "b.go", Line: 2, Author: "b", Body: "outdated one", IsOutdated: true},
+		{ID: 3, Path: "c.go", Line: 3, Author: "c", Body: "current one",
+			ThreadComments: []github.ThreadComment{{Author: "d", Body: long}}},
+	}
+
+	outdated := renderLLMComment(comments[1], LLMOptions{}, llmDetail{})
+	shortened := renderLLMComment(comments[2], LLMOptions{}, llmDetail{maxReplyRunes: longReplyRunes})
+
+	// Room for the outdated comment and a shortened current one, nothing more
+	budget := EstimateTokens(outdated+LLMSeparator) + EstimateTokens(shortened+LLMSeparator)
+	output := RenderLLM(comments, LLMOptions{MaxTokens: budget})
+
+	block, ok := output.Block(comments[2])
+	if !ok {
+		t.Fatal("unresolved, current comment should be kept first")
+	}
+	if !strings.Contains(block, "[truncated 1500 chars]") {
+		t.Errorf("long reply should be truncated:\n%s", block)
+	}
+	if _, ok := output.Block(comments[1]); !ok {
+		t.Error("outdated comment should fit in the remaining budget")
+	}
+	if _, ok := output.Block(comments[0]); ok {
+		t.Error("resolved comment should be dropped first")
+	}
+	if output.Omitted != 1 || output.Shortened != 1 {
+		t.Errorf("Omitted = %d, Shortened = %d, want 1 and 1", output.Omitted, output.Shortened)
+	}
+
+	// Rendering is deterministic
+	again := RenderLLM(comments, LLMOptions{MaxTokens: budget})
+	if b, _ := again.Block(comments[2]); b != block {
+		t.Error("rendering the same input twice should give the same output")
+	}
+}
+
+func TestLLMOutputCharge(t *testing.T) {
+	comments := []*github.ReviewComment{{ID: 1, Path: "a.go", Line: 1, Author: "a", Body: "fix this"}}
+	block := renderLLMComment(comments[0], LLMOptions{}, llmDetail{})
+	summary := "PR_COMMENT_ID: 2\nCOMMENT:\n" + strings.Repeat("bot summary ", 100) + "\n"
+	short := "PR_COMMENT_ID: 3\n"
+
+	// Room for the review comment and the short PR comment, not the bot summary
+	budget := EstimateTokens(block+LLMSeparator) + EstimateTokens(short+LLMSeparator)
+	output := RenderLLM(comments, LLMOptions{MaxTokens: budget})
+	if output.Charge(summary) {
+		t.Error("a block larger than what is left of the budget should not fit")
+	}
+	if !output.Charge(short) {
+		t.Error("a block that fits what is left of the budget should be kept")
+	}
+	if output.Charge(short) {
+		t.Error("the budget should be used up")
+	}
+	if output.Omitted != 2 {
+		t.Errorf("Omitted = %d, want 2", output.Omitted)
+	}
+
+	// Without a budget everything fits
+	if unlimited := RenderLLM(comments, LLMOptions{}); !unlimited.Charge(summary) || unlimited.Omitted != 0 {
+		t.Error("without --max-tokens every block should be kept")
+	}
+}
@@ -0,0 +1,93 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteQuickfix writes one `path:line:col: author: message` line per review thread, the
+// format Vim's quickfix list and Emacs' compilation mode understand out of the box
+func WriteQuickfix(w io.Writer, doc *Document) error {
+	for i := range doc.Comments {
+		c := &doc.Comments[i]
+		if _, err := fmt.Fprintf(w, "%s:%d:1: %s: %s\n", c.Path, quickfixLine(c), c.Author, quickfixMessage(c)); err != nil {
+			return fmt.Errorf("failed to write quickfix output: %w", err)
+		}
+	}
+	return nil
+}
+
+// WriteProblems writes one `path:line:col: severity: author: message` line per review
+// thread, for editors such as VS Code whose problem matchers want a severity. Outdated and
+// resolved threads are reported as info, everything else as a warning.
+func WriteProblems(w io.Writer, doc *Document) error {
+	for i := range doc.Comments {
+		c := &doc.Comments[i]
+		severity := "warning"
+		if c.Outdated || c.Resolved {
+			severity = "info"
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:1: %s: %s: %s\n", c.Path, quickfixLine(c), severity, c.Author, quickfixMessage(c)); err != nil {
+			return fmt.Errorf("failed to write problem output: %w", err)
+		}
+	}
+	return nil
+}
+
+// quickfixLine is the line to jump to: the first commented line, preferring its position in
+// the local checkout. File-level comments point at the top of the file.
+func quickfixLine(c *Comment) int {
+	switch {
+	case c.Scope == "file":
+		return 1
+	case c.Local != nil:
+		return c.Local.StartLine
+	case c.StartLine > 0:
+		return c.StartLine
+	case c.Line > 0:
+		return c.Line
+	case c.OriginalStartLine > 0:
+		return c.OriginalStartLine
+	default:
+		return 1
+	}
+}
+
+// quickfixMessage folds the comment body onto a single line and notes the thread state
+func quickfixMessage(c *Comment) string {
+	var tags []string
+	if c.Resolved {
+		tags = append(tags, "resolved")
+	}
+	if c.Outdated {
+		tags = append(tags, "outdated")
+	}
+	if c.Suggestion != nil {
+		tags = append(tags, "suggestion")
+	}
+	switch len(c.Replies) {
+	case 0:
+	case 1:
+		tags = append(tags, "1 reply")
+	default:
+		tags = append(tags, fmt.Sprintf("%d replies", len(c.Replies)))
+	}
+
+	message := foldLines(c.Body)
+	if len(tags) > 0 {
+		message = fmt.Sprintf("[%s] %s", strings.Join(tags, ", "), message)
+	}
+	return message
+}
+
+// foldLines joins the non-blank lines of text with " ⏎ " so it fits on one line
+func foldLines(text string) string {
+	var parts []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			parts = append(parts, line)
+		}
+	}
+	return strings.Join(parts, " ⏎ ")
+}
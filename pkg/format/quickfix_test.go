@@ -0,0 +1,78 @@
+package format
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestFoldLines(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "single line", text: "looks good", want: "looks good"},
+		{name: "blank lines dropped", text: "first\n\n  second  \n", want: "first ⏎ second"},
+		{name: "empty", text: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := foldLines(tt.text); got != tt.want {
+				t.Errorf("foldLines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuickfixLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment Comment
+		want    int
+	}{
+		{name: "file-level", comment: Comment{Scope: "file"}, want: 1},
+		{name: "local position", comment: Comment{Scope: "line", StartLine: 10, Local: &LocalRange{StartLine: 12, EndLine: 12}}, want: 12},
+		{name: "range start", comment: Comment{Scope: "line", Line: 12, StartLine: 10}, want: 10},
+		{name: "single line", comment: Comment{Scope: "line", Line: 7}, want: 7},
+		{name: "outdated", comment: Comment{Scope: "line", OriginalStartLine: 4}, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := quickfixLine(&tt.comment); got != tt.want {
+				t.Errorf("quickfixLine() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteQuickfix(t *testing.T) {
+	doc := NewDocument("o/r", 1, testComments(), nil, nil)
+
+	var buf bytes.Buffer
+	if err := WriteQuickfix(&buf, doc); err != nil {
+		t.Fatalf("WriteQuickfix() error = %v", err)
+	}
+
+	want := "does/not/exist.go:10:1: alice: [suggestion, 1 reply] use this ⏎ ```suggestion ⏎ return nil ⏎ ```\n" +
+		"README.md:1:1: carol: [resolved] needs a usage section\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteQuickfix() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteProblems(t *testing.T) {
+	doc := NewDocument("o/r", 1, testComments(), nil, nil)
+
+	var buf bytes.Buffer
+	if err := WriteProblems(&buf, doc); err != nil {
+		t.Fatalf("WriteProblems() error = %v", err)
+	}
+
+	want := "does/not/exist.go:10:1: warning: alice: [suggestion, 1 reply] use this ⏎ ```suggestion ⏎ return nil ⏎ ```\n" +
+		"README.md:1:1: info: carol: [resolved] needs a usage section\n"
+	if got := buf.String(); got != want {
+		t.Errorf("WriteProblems() =\n%s\nwant\n%s", got, want)
+	}
+}
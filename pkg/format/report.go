@@ -0,0 +1,339 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/github"
+	"github.com/chmouel/gh-prreview/pkg/ui"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// Report is a pull request's review threads grouped by file, ready to be rendered
+type Report struct {
+	Repository  string
+	PullRequest int
+	GeneratedAt time.Time
+	Threads     int
+	Resolved    int
+	Files       []ReportFile
+}
+
+// ReportFile holds the threads of one file, file-level comments first then by line
+type ReportFile struct {
+	Path    string
+	Threads []ReportThread
+}
+
+// ReportThread is a review thread with the code it refers to
+type ReportThread struct {
+	Comment
+	Location string
+	// Text is the comment body without its suggestion block
+	Text string
+	// Code is the commented lines with some context, from the local checkout when possible
+	Code         []codecontext.Line
+	CodeFromHunk bool
+	// SuggestionDiff replaces the commented lines with the suggested code
+	SuggestionDiff []DiffLine
+}
+
+// DiffLine is one line of a rendered diff. Op is " ", "-" or "+".
+type DiffLine struct {
+	Op   string
+	Text string
+}
+
+// NewReport builds a report from review comments, showing context lines of code around each
+// commented range
+func NewReport(repo string, prNumber int, comments []*github.ReviewComment, context int) *Report {
+	report := &Report{
+		Repository:  repo,
+		PullRequest: prNumber,
+		GeneratedAt: time.Now(),
+		Threads:     len(comments),
+	}
+
+	byPath := make(map[string]*ReportFile)
+	for _, comment := range comments {
+		file, ok := byPath[comment.Path]
+		if !ok {
+			file = &ReportFile{Path: comment.Path}
+			byPath[comment.Path] = file
+		}
+		file.Threads = append(file.Threads, newReportThread(comment, context))
+		if comment.IsResolved() {
+			report.Resolved++
+		}
+	}
+
+	paths := make([]string, 0, len(byPath))
+	for path := range byPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		file := byPath[path]
+		sort.SliceStable(file.Threads, func(i, j int) bool {
+			return reportSortLine(&file.Threads[i]) < reportSortLine(&file.Threads[j])
+		})
+		report.Files = append(report.Files, *file)
+	}
+
+	return report
+}
+
+func newReportThread(comment *github.ReviewComment, context int) ReportThread {
+	thread := ReportThread{
+		Comment:  NewComment(comment),
+		Location: comment.Location(),
+		Text:     ui.StripSuggestionBlock(comment.Body),
+	}
+
+	if comment.IsFileLevel() {
+		return thread
+	}
+
+	snippet, err := codecontext.ForComment(comment, context)
+	if err != nil {
+		return thread
+	}
+	thread.Code = snippet.Lines
+	thread.CodeFromHunk = snippet.FromHunk
+
+	if comment.HasSuggestion {
+		thread.SuggestionDiff = suggestionDiff(snippet.Lines, comment.SuggestedCode)
+	}
+	return thread
+}
+
+// suggestionDiff renders a suggestion as the removal of the marked lines followed by the
+// suggested code, keeping the surrounding context lines
+func suggestionDiff(lines []codecontext.Line, suggestion string) []DiffLine {
+	var diff []DiffLine
+	inserted := false
+	for _, line := range lines {
+		if line.Marked {
+			diff = append(diff, DiffLine{Op: "-", Text: line.Text})
+			continue
+		}
+		if !inserted && len(diff) > 0 && diff[len(diff)-1].Op == "-" {
+			diff = appendSuggestion(diff, suggestion)
+			inserted = true
+		}
+		diff = append(diff, DiffLine{Op: " ", Text: line.Text})
+	}
+	if !inserted {
+		diff = appendSuggestion(diff, suggestion)
+	}
+	return diff
+}
+
+func appendSuggestion(diff []DiffLine, suggestion string) []DiffLine {
+	if suggestion == "" {
+		return diff
+	}
+	for _, line := range strings.Split(suggestion, "\n") {
+		diff = append(diff, DiffLine{Op: "+", Text: line})
+	}
+	return diff
+}
+
+func reportSortLine(thread *ReportThread) int {
+	if thread.Scope == "file" {
+		return 0
+	}
+	return quickfixLine(&thread.Comment)
+}
+
+// Status is a short description of the thread state
+func (t ReportThread) Status() string {
+	var states []string
+	if t.Resolved {
+		states = append(states, "resolved")
+	} else {
+		states = append(states, "unresolved")
+	}
+	if t.Outdated {
+		states = append(states, "outdated")
+	}
+	return strings.Join(states, ", ")
+}
+
+// Language is the code fence language of the thread's file
+func (t ReportThread) Language() string {
+	if language := ui.DetectLanguage(t.Path); language != "unknown" {
+		return language
+	}
+	return ""
+}
+
+// WriteMarkdown renders the report as a Markdown document
+func WriteMarkdown(w io.Writer, report *Report) error {
+	if err := markdownReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render Markdown report: %w", err)
+	}
+	return nil
+}
+
+// WriteHTML renders the report as a self-contained HTML page
+func WriteHTML(w io.Writer, report *Report) error {
+	if err := htmlReportTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+	return nil
+}
+
+var reportMarkdown = goldmark.New(goldmark.WithExtensions(extension.GFM))
+
+// markdownToHTML renders a comment body. goldmark escapes raw HTML by default, so the result
+// is safe to embed.
+func markdownToHTML(body string) template.HTML {
+	var buf bytes.Buffer
+	if err := reportMarkdown.Convert([]byte(body), &buf); err != nil {
+		return template.HTML("<pre>" + template.HTMLEscapeString(body) + "</pre>")
+	}
+	return template.HTML(buf.String())
+}
+
+// fence returns a code fence long enough not to be closed by the code it wraps
+func fence(code string) string {
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return fence
+}
+
+func codeText(lines []codecontext.Line) string {
+	var b strings.Builder
+	for _, line := range lines {
+		marker := " "
+		if line.Marked {
+			marker = ">"
+		}
+		fmt.Fprintf(&b, "%s %4d | %s\n", marker, line.Number, line.Text)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func diffText(lines []DiffLine) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(line.Op + line.Text + "\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func indentReply(body string) string {
+	return "> " + strings.ReplaceAll(strings.TrimSpace(body), "\n", "\n> ")
+}
+
+var markdownReportTemplate = texttemplate.Must(texttemplate.New("report.md").Funcs(texttemplate.FuncMap{
+	"fence":    fence,
+	"code":     codeText,
+	"diff":     diffText,
+	"quote":    indentReply,
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+}).Parse(`# Review of {{.Repository}}#{{.PullRequest}}
+
+{{.Threads}} thread(s), {{.Resolved}} resolved. Generated {{datetime .GeneratedAt}}.
+{{range .Files}}
+## ` + "`{{.Path}}`" + `
+{{range .Threads}}
+### [{{.Location}}]({{.URL}}) by @{{.Author}}
+
+*{{.Status}}* · {{datetime .CreatedAt}}
+{{if .Text}}
+{{.Text}}
+{{end}}{{if .Code}}{{$code := code .Code}}
+{{if .CodeFromHunk}}Code (from the diff hunk):{{else}}Code:{{end}}
+
+{{fence $code}}
+{{$code}}
+{{fence $code}}
+{{end}}{{if .SuggestionDiff}}{{$diff := diff .SuggestionDiff}}
+Suggested change:
+
+{{fence $diff}}diff
+{{$diff}}
+{{fence $diff}}
+{{else if .Suggestion}}{{$code := .Suggestion.Code}}
+Suggested change:
+
+{{fence $code}}{{.Language}}
+{{$code}}
+{{fence $code}}
+{{end}}{{range .Replies}}
+**@{{.Author}}** replied ({{datetime .CreatedAt}}):
+
+{{quote .Body}}
+{{end}}{{end}}{{end}}`))
+
+var htmlReportTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"markdown": markdownToHTML,
+	"datetime": func(t time.Time) string { return t.UTC().Format("2006-01-02 15:04 MST") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Review of {{.Repository}}#{{.PullRequest}}</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #1f2328; line-height: 1.5; }
+h2 { font-family: ui-monospace, SFMono-Regular, Menlo, monospace; font-size: 1.1em; border-bottom: 1px solid #d0d7de; padding-bottom: .3em; margin-top: 2em; }
+.thread { border: 1px solid #d0d7de; border-radius: 6px; margin: 1em 0; padding: 0 1em 1em; }
+.thread.resolved { opacity: .7; }
+.meta { color: #59636e; font-size: .9em; }
+.badge { display: inline-block; border-radius: 2em; padding: 0 .6em; font-size: .8em; font-weight: 600; margin-left: .3em; background: #fff8c5; }
+.badge.resolved { background: #dafbe1; }
+.badge.outdated { background: #eaeef2; }
+pre { background: #f6f8fa; border-radius: 6px; padding: .8em; overflow-x: auto; font-size: .85em; }
+.code > span, .diff > span { display: block; white-space: pre; }
+.code .marked { background: #fff8c5; }
+.code .num { color: #8c959f; user-select: none; }
+.diff .add { background: #dafbe1; }
+.diff .del { background: #ffebe9; }
+.reply { border-left: 3px solid #d0d7de; padding-left: 1em; margin-top: 1em; }
+</style>
+</head>
+<body>
+<h1>Review of {{.Repository}}#{{.PullRequest}}</h1>
+<p class="meta">{{.Threads}} thread(s), {{.Resolved}} resolved. Generated {{datetime .GeneratedAt}}.</p>
+{{range .Files}}
+<h2>{{.Path}}</h2>
+{{range .Threads}}
+<div class="thread{{if .Resolved}} resolved{{end}}">
+<h3><a href="{{.URL}}">{{.Location}}</a> by @{{.Author}}
+{{if .Resolved}}<span class="badge resolved">resolved</span>{{else}}<span class="badge">unresolved</span>{{end}}
+{{if .Outdated}}<span class="badge outdated">outdated</span>{{end}}</h3>
+<p class="meta">{{datetime .CreatedAt}}</p>
+{{if .Text}}{{markdown .Text}}{{end}}
+{{if .Code}}<p class="meta">{{if .CodeFromHunk}}Code (from the diff hunk){{else}}Code{{end}}</p>
+<pre class="code">{{range .Code}}<span{{if .Marked}} class="marked"{{end}}><span class="num">{{printf "%4d" .Number}} </span>{{.Text}}</span>{{end}}</pre>
+{{end}}
+{{if .SuggestionDiff}}<p class="meta">Suggested change</p>
+<pre class="diff">{{range .SuggestionDiff}}<span{{if eq .Op "+"}} class="add"{{else if eq .Op "-"}} class="del"{{end}}>{{.Op}}{{.Text}}</span>{{end}}</pre>
+{{else if .Suggestion}}<p class="meta">Suggested change</p>
+<pre>{{.Suggestion.Code}}</pre>
+{{end}}
+{{range .Replies}}
+<div class="reply">
+<p class="meta"><strong>@{{.Author}}</strong> replied · <a href="{{.URL}}">{{datetime .CreatedAt}}</a></p>
+{{markdown .Body}}
+</div>
+{{end}}
+</div>
+{{end}}
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,129 @@
+package format
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/codecontext"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func TestSuggestionDiff(t *testing.T) {
+	tests := []struct {
+		name       string
+		lines      []codecontext.Line
+		suggestion string
+		want       []DiffLine
+	}{
+		{
+			name: "context around the replaced lines",
+			lines: []codecontext.Line{
+				{Number: 1, Text: "before"},
+				{Number: 2, Text: "old", Marked: true},
+				{Number: 3, Text: "after"},
+			},
+			suggestion: "new one\nnew two",
+			want: []DiffLine{
+				{Op: " ", Text: "before"},
+				{Op: "-", Text: "old"},
+				{Op: "+", Text: "new one"},
+				{Op: "+", Text: "new two"},
+				{Op: " ", Text: "after"},
+			},
+		},
+		{
+			name:       "marked lines at the end",
+			lines:      []codecontext.Line{{Number: 1, Text: "old", Marked: true}},
+			suggestion: "new",
+			want: []DiffLine{
+				{Op: "-", Text: "old"},
+				{Op: "+", Text: "new"},
+			},
+		},
+		{
+			name:       "suggestion deleting the lines",
+			lines:      []codecontext.Line{{Number: 1, Text: "old", Marked: true}},
+			suggestion: "",
+			want:       []DiffLine{{Op: "-", Text: "old"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suggestionDiff(tt.lines, tt.suggestion); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("suggestionDiff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	comments := append(testComments(),
+		&github.ReviewComment{ID: 4, Path: "README.md", Line: 3, EndLine: 3, SubjectType: "line", Author: "dave", Body: "typo"},
+	)
+	report := NewReport("o/r", 1, comments, 1)
+
+	if report.Threads != 3 || report.Resolved != 1 {
+		t.Errorf("Threads = %d, Resolved = %d, want 3 and 1", report.Threads, report.Resolved)
+	}
+
+	var paths []string
+	for _, file := range report.Files {
+		paths = append(paths, file.Path)
+	}
+	if want := []string{"README.md", "does/not/exist.go"}; !reflect.DeepEqual(paths, want) {
+		t.Fatalf("files = %v, want %v", paths, want)
+	}
+
+	readme := report.Files[0].Threads
+	if len(readme) != 2 || readme[0].Scope != "file" || readme[1].ID != 4 {
+		t.Errorf("file-level comment should come first, got %+v", readme)
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	report := NewReport("o/r", 1, testComments(), 0)
+
+	var buf bytes.Buffer
+	if err := WriteMarkdown(&buf, report); err != nil {
+		t.Fatalf("WriteMarkdown() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"# Review of o/r#1",
+		"## `README.md`",
+		"### [README.md](",
+		"*resolved*",
+		"**@bob** replied",
+		"> done",
+		"Suggested change:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Markdown report is missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	comments := []*github.ReviewComment{{
+		ID: 1, Path: "main.go", SubjectType: "file", Author: "eve",
+		Body:    "**bold** <script>alert(1)</script>",
+		HTMLURL: "https://github.com/o/r/pull/1#discussion_r1",
+	}}
+
+	var buf bytes.Buffer
+	if err := WriteHTML(&buf, NewReport("o/r", 1, comments, 0)); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "<strong>bold</strong>") {
+		t.Errorf("comment body should be rendered as Markdown:\n%s", out)
+	}
+	if strings.Contains(out, "<script>") {
+		t.Errorf("raw HTML from the comment body must not be passed through:\n%s", out)
+	}
+}
@@ -0,0 +1,255 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+
+	// sarifSrcRoot is the base the artifact URIs are relative to: the repository checkout
+	sarifSrcRoot = "%SRCROOT%"
+
+	ruleReviewComment = "review-comment"
+	ruleSuggestion    = "review-suggestion"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool           sarifTool                    `json:"tool"`
+	VersionControl []sarifVersionControlDetails `json:"versionControlProvenance,omitempty"`
+	Results        []sarifResult                `json:"results"`
+	Properties     map[string]any               `json:"properties,omitempty"`
+}
+
+type sarifVersionControlDetails struct {
+	RepositoryURI string `json:"repositoryUri"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	Name             string       `json:"name"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string             `json:"ruleId"`
+	Level               string             `json:"level"`
+	Message             sarifMessage       `json:"message"`
+	Locations           []sarifLocation    `json:"locations"`
+	PartialFingerprints map[string]string  `json:"partialFingerprints,omitempty"`
+	Fixes               []sarifFix         `json:"fixes,omitempty"`
+	Suppressions        []sarifSuppression `json:"suppressions,omitempty"`
+	Properties          map[string]any     `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+	Region           *sarifRegion     `json:"region,omitempty"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine,omitempty"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLoc   `json:"artifactLocation"`
+	Replacements     []sarifReplacement `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion           `json:"deletedRegion"`
+	InsertedContent *sarifArtifactContent `json:"insertedContent,omitempty"`
+}
+
+type sarifArtifactContent struct {
+	Text string `json:"text"`
+}
+
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Status        string `json:"status"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// WriteSARIF writes the document's review comments as a SARIF 2.1.0 log. Each review thread
+// becomes a result located at the commented lines; suggestions carry a fix replacing those
+// lines, and resolved threads are reported as suppressed.
+func WriteSARIF(w io.Writer, doc *Document) error {
+	results := make([]sarifResult, 0, len(doc.Comments))
+	for i := range doc.Comments {
+		results = append(results, newSARIFResult(&doc.Comments[i]))
+	}
+
+	log := sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "gh-prreview",
+				InformationURI: "https://github.com/chmouel/gh-prreview",
+				Rules: []sarifRule{
+					{
+						ID:               ruleReviewComment,
+						Name:             "ReviewComment",
+						ShortDescription: sarifMessage{Text: "Pull request review comment"},
+					},
+					{
+						ID:               ruleSuggestion,
+						Name:             "ReviewSuggestion",
+						ShortDescription: sarifMessage{Text: "Pull request review comment with a suggested change"},
+					},
+				},
+			}},
+			VersionControl: []sarifVersionControlDetails{
+				{RepositoryURI: repositoryURL(doc)},
+			},
+			Results: results,
+			Properties: map[string]any{
+				"repository":   doc.Repository,
+				"pull_request": doc.PullRequest,
+			},
+		}},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return nil
+}
+
+// repositoryURL returns the web URL of the repository, on github.com unless the document
+// says otherwise
+func repositoryURL(doc *Document) string {
+	if doc.RepositoryURL != "" {
+		return doc.RepositoryURL
+	}
+	return "https://github.com/" + doc.Repository
+}
+
+// newSARIFResult maps a review thread to a SARIF result
+func newSARIFResult(c *Comment) sarifResult {
+	artifact := sarifArtifactLoc{URI: c.Path, URIBaseID: sarifSrcRoot}
+	region := sarifRegionFor(c)
+
+	result := sarifResult{
+		RuleID: ruleReviewComment,
+		Level:  "warning",
+		Message: sarifMessage{
+			Text:     fmt.Sprintf("@%s: %s", c.Author, c.Body),
+			Markdown: fmt.Sprintf("**@%s**: %s", c.Author, c.Body),
+		},
+		Locations: []sarifLocation{{
+			PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact, Region: region},
+		}},
+		PartialFingerprints: map[string]string{
+			"reviewCommentId": fmt.Sprintf("%d", c.ID),
+		},
+		Properties: map[string]any{
+			"author":   c.Author,
+			"url":      c.URL,
+			"scope":    c.Scope,
+			"outdated": c.Outdated,
+			"replies":  len(c.Replies),
+		},
+	}
+	if c.ThreadID != "" {
+		result.Properties["thread_id"] = c.ThreadID
+	}
+
+	// Outdated comments point at code that has since changed, so demote them
+	if c.Outdated {
+		result.Level = "note"
+	}
+
+	// Suggestions replace the commented lines of the new file; on the left side or without
+	// a region there is nothing a consumer could apply them to
+	if c.Suggestion != nil {
+		result.RuleID = ruleSuggestion
+		if region != nil && c.Side != "LEFT" {
+			result.Fixes = []sarifFix{{
+				Description: sarifMessage{Text: fmt.Sprintf("Apply suggestion from @%s", c.Author)},
+				ArtifactChanges: []sarifArtifactChange{{
+					ArtifactLocation: artifact,
+					Replacements: []sarifReplacement{{
+						DeletedRegion:   *region,
+						InsertedContent: &sarifArtifactContent{Text: c.Suggestion.Code},
+					}},
+				}},
+			}}
+		}
+	}
+
+	if c.Resolved {
+		result.Suppressions = []sarifSuppression{{
+			Kind:          "external",
+			Status:        "accepted",
+			Justification: "Review thread resolved on GitHub",
+		}}
+	}
+
+	return result
+}
+
+// sarifRegionFor returns the lines a comment covers, preferring where they are in the local
+// checkout. File-level comments have no region.
+func sarifRegionFor(c *Comment) *sarifRegion {
+	if c.Scope == "file" {
+		return nil
+	}
+
+	start, end := c.StartLine, c.EndLine
+	if c.Local != nil {
+		start, end = c.Local.StartLine, c.Local.EndLine
+	}
+	if end == 0 {
+		end = c.Line
+	}
+	if start == 0 {
+		start = end
+	}
+	if start == 0 {
+		return nil
+	}
+	return &sarifRegion{StartLine: start, EndLine: end}
+}
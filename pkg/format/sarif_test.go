@@ -0,0 +1,98 @@
+package format
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSARIFRegionFor(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment Comment
+		want    *sarifRegion
+	}{
+		{
+			name:    "file-level comment",
+			comment: Comment{Scope: "file"},
+			want:    nil,
+		},
+		{
+			name:    "multi-line range",
+			comment: Comment{Scope: "line", Line: 12, StartLine: 10, EndLine: 12},
+			want:    &sarifRegion{StartLine: 10, EndLine: 12},
+		},
+		{
+			name:    "local range wins",
+			comment: Comment{Scope: "line", StartLine: 10, EndLine: 12, Local: &LocalRange{StartLine: 14, EndLine: 16}},
+			want:    &sarifRegion{StartLine: 14, EndLine: 16},
+		},
+		{
+			name:    "outdated comment with only a line",
+			comment: Comment{Scope: "line", Line: 7},
+			want:    &sarifRegion{StartLine: 7, EndLine: 7},
+		},
+		{
+			name:    "no position at all",
+			comment: Comment{Scope: "line"},
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sarifRegionFor(&tt.comment); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("sarifRegionFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	doc := NewDocument("o/r", 1, testComments(), nil, nil)
+
+	var buf bytes.Buffer
+	if err := WriteSARIF(&buf, doc); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if log.Version != sarifVersion || len(log.Runs) != 1 {
+		t.Fatalf("unexpected SARIF envelope: version %q, %d runs", log.Version, len(log.Runs))
+	}
+
+	results := log.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+
+	suggestion := results[0]
+	if suggestion.RuleID != ruleSuggestion {
+		t.Errorf("ruleId = %q, want %q", suggestion.RuleID, ruleSuggestion)
+	}
+	if len(suggestion.Fixes) != 1 {
+		t.Fatalf("expected one fix, got %d", len(suggestion.Fixes))
+	}
+	replacement := suggestion.Fixes[0].ArtifactChanges[0].Replacements[0]
+	if replacement.DeletedRegion != (sarifRegion{StartLine: 10, EndLine: 12}) {
+		t.Errorf("deletedRegion = %+v", replacement.DeletedRegion)
+	}
+	if replacement.InsertedContent == nil || replacement.InsertedContent.Text != "return nil" {
+		t.Errorf("insertedContent = %+v", replacement.InsertedContent)
+	}
+	if len(suggestion.Suppressions) != 0 {
+		t.Error("unresolved thread should not be suppressed")
+	}
+
+	file := results[1]
+	if file.RuleID != ruleReviewComment || file.Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("file-level comment should have no region: %+v", file.Locations[0])
+	}
+	if len(file.Suppressions) != 1 || file.Suppressions[0].Kind != "external" {
+		t.Errorf("resolved thread should be suppressed, got %+v", file.Suppressions)
+	}
+}
@@ -0,0 +1,180 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// SetTransport replaces the HTTP transport used to reach the GitHub API, e.g. to point the
+// client at a test server. Requests still go through the rate limit retries.
+func (c *Client) SetTransport(transport http.RoundTripper) {
+	c.transport = transport
+	c.httpClient = nil
+	c.graphQLClient = nil
+}
+
+// apiClients sets up the in-process REST and GraphQL clients on first use, authenticated with
+// the token gh has for the host
+func (c *Client) apiClients() error {
+	if c.httpClient != nil {
+		return nil
+	}
+
+	host := c.Host()
+	token := c.authToken
+	if token == "" {
+		token, _ = auth.TokenForHost(host)
+		if token == "" {
+			return fmt.Errorf("not logged in to %s (run: gh auth login --hostname %s)", host, host)
+		}
+	}
+
+	opts := api.ClientOptions{
+		Host:      host,
+		AuthToken: token,
+		Transport: newRetryTransport(c.transport, c.debugLog),
+	}
+
+	httpClient, err := api.NewHTTPClient(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create the API client: %w", err)
+	}
+	graphQLClient, err := api.NewGraphQLClient(opts)
+	if err != nil {
+		return fmt.Errorf("failed to create the GraphQL client: %w", err)
+	}
+
+	c.httpClient = httpClient
+	c.graphQLClient = graphQLClient
+	c.restURL = restBaseURL(host)
+	return nil
+}
+
+// restBaseURL returns the REST API root of a host: api.github.com, or /api/v3 on GitHub
+// Enterprise Server
+func restBaseURL(host string) string {
+	if strings.EqualFold(host, defaultHost) {
+		return "https://api.github.com/"
+	}
+	return fmt.Sprintf("https://%s/api/v3/", host)
+}
+
+// restRequest sends a REST request, with body encoded as JSON when set. Responses with a
+// non-2xx status are returned as an *api.HTTPError.
+func (c *Client) restRequest(method, path string, header http.Header, body any) (*http.Response, error) {
+	if err := c.checkOnline(); err != nil {
+		return nil, err
+	}
+	if err := c.apiClients(); err != nil {
+		return nil, err
+	}
+
+	url := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		url = c.restURL + strings.TrimPrefix(path, "/")
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	c.debugLog("%s %s", method, url)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		return nil, api.HandleHTTPError(resp)
+	}
+	return resp, nil
+}
+
+// restDo sends a REST request and decodes the JSON response into result, unless it is nil
+func (c *Client) restDo(method, path string, body, result any) error {
+	resp, err := c.restRequest(method, path, nil, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// restGetAll fetches every page of a REST list, following the Link headers
+func restGetAll[T any](c *Client, path string) ([]T, error) {
+	var items []T
+	for path != "" {
+		resp, err := c.restRequest(http.MethodGet, path, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []T
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		items = append(items, page...)
+
+		path = nextPageURL(resp.Header.Get("Link"))
+	}
+	return items, nil
+}
+
+// nextPageURL returns the rel="next" URL of a Link header, or "" on the last page
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		target, params, ok := strings.Cut(part, ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(target), "<>")
+	}
+	return ""
+}
+
+// graphQL runs a GraphQL query and decodes its data into result. Errors reported by the API
+// are returned as an *api.GraphQLError.
+func (c *Client) graphQL(query string, variables map[string]any, result any) error {
+	if err := c.checkOnline(); err != nil {
+		return err
+	}
+	if err := c.apiClients(); err != nil {
+		return err
+	}
+
+	if err := c.graphQLClient.Do(query, variables, result); err != nil {
+		c.debugLog("GraphQL query failed: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,331 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// CacheDirEnv overrides where the review data is cached
+	CacheDirEnv = "GH_PRREVIEW_CACHE_DIR"
+
+	cacheVersion = 1
+
+	// cacheFullRefreshAge is how long incremental refreshes are used before fetching every
+	// comment again, which is the only way to notice deleted comments
+	cacheFullRefreshAge = 24 * time.Hour
+)
+
+// ErrOffline is returned for operations that need GitHub while in offline mode
+var ErrOffline = errors.New("not available in offline mode")
+
+// Cache stores the review data of pull requests on disk so it can be refreshed incrementally
+// and used offline
+type Cache struct {
+	dir string
+}
+
+// cacheEntry is the cached review data of one pull request
+type cacheEntry struct {
+	Version     int                   `json:"version"`
+	Repo        string                `json:"repo"` // [HOST/]OWNER/REPO
+	PullRequest int                   `json:"pull_request"`
+	FetchedAt   time.Time             `json:"fetched_at"`
+	FullFetchAt time.Time             `json:"full_fetch_at"`
+	Comments    []rawReviewComment    `json:"comments"`
+	Threads     map[int64]*ThreadInfo `json:"threads"`
+}
+
+// checkout is what is remembered about a local clone, to work out the repository and pull
+// request offline
+type checkout struct {
+	Repo     string         `json:"repo"` // [HOST/]OWNER/REPO
+	Branches map[string]int `json:"branches"`
+}
+
+// NewCache returns a cache storing its files under dir
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// DefaultCacheDir returns $GH_PRREVIEW_CACHE_DIR, or gh-prreview in the user cache directory
+func DefaultCacheDir() (string, error) {
+	if dir := os.Getenv(CacheDirEnv); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate the cache directory: %w", err)
+	}
+	return filepath.Join(dir, "gh-prreview"), nil
+}
+
+// entryPath returns where the data of a pull request is cached. The repository comes from
+// the command line or a URL, so its segments are checked to stay within the cache directory.
+func (c *Cache) entryPath(repo string, prNumber int) (string, error) {
+	segments := strings.Split(repo, "/")
+	if len(segments) < 2 || len(segments) > 3 {
+		return "", fmt.Errorf("invalid repository %q for the cache", repo)
+	}
+	for _, segment := range segments {
+		if segment == "" || segment == "." || segment == ".." || strings.Contains(segment, `\`) {
+			return "", fmt.Errorf("invalid repository %q for the cache", repo)
+		}
+	}
+	return filepath.Join(c.dir, filepath.Join(segments...), fmt.Sprintf("pr-%d.json", prNumber)), nil
+}
+
+// load returns the cached data of a pull request, or nil when there is none
+func (c *Cache) load(repo string, prNumber int) (*cacheEntry, error) {
+	path, err := c.entryPath(repo, prNumber)
+	if err != nil {
+		return nil, err
+	}
+	var entry cacheEntry
+	if err := readJSONFile(path, &entry); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if entry.Version != cacheVersion {
+		return nil, nil
+	}
+	return &entry, nil
+}
+
+func (c *Cache) save(entry *cacheEntry) error {
+	entry.Version = cacheVersion
+	path, err := c.entryPath(entry.Repo, entry.PullRequest)
+	if err != nil {
+		return err
+	}
+	return writeJSONFile(path, entry)
+}
+
+func (c *Cache) checkoutsPath() string {
+	return filepath.Join(c.dir, "checkouts.json")
+}
+
+func (c *Cache) loadCheckouts() map[string]*checkout {
+	checkouts := make(map[string]*checkout)
+	if err := readJSONFile(c.checkoutsPath(), &checkouts); err != nil {
+		return make(map[string]*checkout)
+	}
+	return checkouts
+}
+
+// rememberCheckout records the repository, and optionally the pull request of a branch, of
+// the local clone at toplevel
+func (c *Cache) rememberCheckout(toplevel, repo, branch string, prNumber int) error {
+	checkouts := c.loadCheckouts()
+	co, ok := checkouts[toplevel]
+	if !ok || co.Repo != repo {
+		co = &checkout{Repo: repo, Branches: make(map[string]int)}
+		checkouts[toplevel] = co
+	}
+	if branch != "" && prNumber > 0 {
+		if co.Branches[branch] == prNumber {
+			return nil
+		}
+		co.Branches[branch] = prNumber
+	} else if ok && co.Repo == repo {
+		return nil
+	}
+	return writeJSONFile(c.checkoutsPath(), checkouts)
+}
+
+// lookupCheckout returns what was remembered about the local clone at toplevel
+func (c *Cache) lookupCheckout(toplevel string) *checkout {
+	return c.loadCheckouts()[toplevel]
+}
+
+func readJSONFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return nil
+}
+
+// writeJSONFile writes v to path atomically, so a concurrent reader never sees a partial file
+func writeJSONFile(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// mergeRawComments updates cached comments with the ones changed since, keeping them in the
+// order of the REST API (by ID)
+func mergeRawComments(cached, changed []rawReviewComment) []rawReviewComment {
+	byID := make(map[int64]rawReviewComment, len(cached)+len(changed))
+	for _, comment := range cached {
+		byID[comment.ID] = comment
+	}
+	for _, comment := range changed {
+		byID[comment.ID] = comment
+	}
+
+	merged := make([]rawReviewComment, 0, len(byID))
+	for _, comment := range byID {
+		merged = append(merged, comment)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].ID < merged[j].ID })
+	return merged
+}
+
+// latestUpdate returns when the most recently updated comment changed, in GitHub's clock
+func latestUpdate(comments []rawReviewComment) time.Time {
+	var latest time.Time
+	for _, comment := range comments {
+		if comment.UpdatedAt.After(latest) {
+			latest = comment.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// SetCache enables caching review data in cache
+func (c *Client) SetCache(cache *Cache) {
+	c.cache = cache
+}
+
+// SetOffline makes the client serve review comments from the cache only and refuse every
+// operation that needs GitHub
+func (c *Client) SetOffline(offline bool) {
+	c.offline = offline
+}
+
+// checkOnline fails in offline mode
+func (c *Client) checkOnline() error {
+	if c.offline {
+		return ErrOffline
+	}
+	return nil
+}
+
+// fetchReviewCommentsCached returns the review comments of a PR, only fetching the comments
+// updated since the cached copy. Thread states are always re-read since resolving a thread
+// does not update its comments. In offline mode the cached copy is returned as is.
+func (c *Client) fetchReviewCommentsCached(repo string, prNumber int) ([]*ReviewComment, error) {
+	cacheKey := qualifiedRepo(c.Host(), repo)
+	entry, err := c.cache.load(cacheKey, prNumber)
+	if err != nil {
+		c.debugLog("Ignoring unreadable cache: %v", err)
+		entry = nil
+	}
+
+	if c.offline {
+		if entry == nil {
+			return nil, fmt.Errorf("no cached review data for %s#%d; run once without --offline", repo, prNumber)
+		}
+		c.debugLog("Using review data of %s#%d cached at %s", repo, prNumber, entry.FetchedAt.Format(time.RFC3339))
+		return c.buildReviewComments(entry.Comments, entry.Threads), nil
+	}
+
+	now := time.Now()
+	var rawComments []rawReviewComment
+	fullFetchAt := now
+	if entry != nil && now.Sub(entry.FullFetchAt) < cacheFullRefreshAge {
+		since := latestUpdate(entry.Comments)
+		c.debugLog("Refreshing cached review comments of %s#%d updated since %s", repo, prNumber, since.Format(time.RFC3339))
+		changed, err := c.fetchRawReviewComments(repo, prNumber, since)
+		if err != nil {
+			return nil, err
+		}
+		rawComments = mergeRawComments(entry.Comments, changed)
+		fullFetchAt = entry.FullFetchAt
+	} else {
+		rawComments, err = c.fetchRawReviewComments(repo, prNumber, time.Time{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	reviewThreads, err := c.getReviewThreads(repo, prNumber)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not fetch review threads: %v\n", err)
+		reviewThreads = make(map[int64]*ThreadInfo)
+		if entry != nil && entry.Threads != nil {
+			reviewThreads = entry.Threads
+		}
+	}
+
+	if err := c.cache.save(&cacheEntry{
+		Repo:        cacheKey,
+		PullRequest: prNumber,
+		FetchedAt:   now,
+		FullFetchAt: fullFetchAt,
+		Comments:    rawComments,
+		Threads:     reviewThreads,
+	}); err != nil {
+		c.debugLog("Failed to update the cache: %v", err)
+	}
+
+	return c.buildReviewComments(rawComments, reviewThreads), nil
+}
+
+// gitOutput runs a git command and returns its trimmed output
+func gitOutput(args ...string) (string, error) {
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// cachedCheckout returns what the cache remembers about the current local clone
+func (c *Client) cachedCheckout() *checkout {
+	if c.cache == nil {
+		return nil
+	}
+	toplevel, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil
+	}
+	return c.cache.lookupCheckout(toplevel)
+}
+
+// rememberCheckout records the repository of the current local clone, and the PR of branch
+// when set, so they can be worked out in offline mode
+func (c *Client) rememberCheckout(repo, branch string, prNumber int) {
+	if c.cache == nil || repo == "" {
+		return
+	}
+	toplevel, err := gitOutput("rev-parse", "--show-toplevel")
+	if err != nil {
+		return
+	}
+	if err := c.cache.rememberCheckout(toplevel, repo, branch, prNumber); err != nil {
+		c.debugLog("Failed to update the cache: %v", err)
+	}
+}
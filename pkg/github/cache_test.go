@@ -0,0 +1,38 @@
+package github
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCacheEntryPath(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewCache(dir)
+
+	tests := []struct {
+		repo    string
+		want    string
+		wantErr bool
+	}{
+		{repo: "owner/repo", want: filepath.Join(dir, "owner", "repo", "pr-42.json")},
+		{repo: "ghe.example.com/owner/repo", want: filepath.Join(dir, "ghe.example.com", "owner", "repo", "pr-42.json")},
+		{repo: "../../etc", wantErr: true},
+		{repo: "owner/..", wantErr: true},
+		{repo: "ghe.example.com/../repo", wantErr: true},
+		{repo: `owner/..\..\repo`, wantErr: true},
+		{repo: "owner//repo", wantErr: true},
+		{repo: "repo", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.repo, func(t *testing.T) {
+			got, err := cache.entryPath(tt.repo, 42)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("entryPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("entryPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,23 +3,45 @@ package github
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/chmouel/gh-prreview/pkg/diffposition"
 	"github.com/chmouel/gh-prreview/pkg/parser"
 	"github.com/cli/go-gh/v2"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 )
 
 type Client struct {
-	repo  string
-	debug bool
+	repo    string
+	host    string
+	debug   bool
+	cache   *Cache
+	offline bool
+	exec    execFunc
+
+	// In-process API clients, set up on first use
+	transport     http.RoundTripper
+	authToken     string
+	httpClient    *http.Client
+	graphQLClient *api.GraphQLClient
+	restURL       string
+	login         string // the authenticated user, once looked up
 }
 
+// execFunc runs a gh command, gh.Exec unless replaced in tests. Only the commands working out
+// the repository and PR of the local checkout go through gh.
+type execFunc func(args ...string) (stdout, stderr bytes.Buffer, err error)
+
 type ReviewComment struct {
 	ID                int64
 	ThreadID          string // GraphQL node ID for resolving the thread
+	ReviewID          int64  // ID of the review this comment was submitted with
 	Path              string
 	Line              int
 	Body              string
@@ -34,26 +56,76 @@ type ReviewComment struct {
 	OriginalEndLine   int
 	DiffHunk          string
 	DiffSide          diffposition.DiffSide
-	SubjectType       string
+	SubjectType       string // "line" or "file"
 	HTMLURL           string
 	IsOutdated        bool
+	Resolved          bool
+	CreatedAt         time.Time
+	Reactions         Reactions
 	ThreadComments    []ThreadComment
 }
 
 type ThreadComment struct {
-	ID      int64
-	Body    string
-	Author  string
-	HTMLURL string
+	ID        int64
+	Body      string
+	Author    string
+	HTMLURL   string
+	CreatedAt time.Time
+	Reactions Reactions
+}
+
+// IssueComment is a general comment posted in the PR conversation tab
+type IssueComment struct {
+	ID        int64
+	Body      string
+	Author    string
+	HTMLURL   string
+	CreatedAt time.Time
+}
+
+// Review represents a submitted (or pending) pull request review
+type Review struct {
+	ID          int64
+	NodeID      string // GraphQL node ID, to add comments to a pending review
+	Author      string
+	State       string // APPROVED, CHANGES_REQUESTED, COMMENTED, DISMISSED or PENDING
+	Body        string
+	HTMLURL     string
+	SubmittedAt time.Time
 }
 
 // IsResolved returns true if the comment thread has been marked as resolved/done
 func (rc *ReviewComment) IsResolved() bool {
-	return rc.SubjectType == "resolved"
+	return rc.Resolved
+}
+
+// IsFileLevel returns true if the comment applies to the whole file rather than to lines
+func (rc *ReviewComment) IsFileLevel() bool {
+	return rc.SubjectType == "file"
+}
+
+// IsMultiLine returns true if the comment spans more than one line
+func (rc *ReviewComment) IsMultiLine() bool {
+	return !rc.IsFileLevel() && rc.StartLine > 0 && rc.StartLine < rc.EndLine
+}
+
+// Location returns a human readable location: "path" for file-level comments,
+// "path:start-end" for multi-line comments and "path:line" otherwise
+func (rc *ReviewComment) Location() string {
+	switch {
+	case rc.IsFileLevel():
+		return rc.Path
+	case rc.IsMultiLine():
+		return fmt.Sprintf("%s:%d-%d", rc.Path, rc.StartLine, rc.EndLine)
+	case rc.Line == 0 && rc.OriginalLine > 0:
+		return fmt.Sprintf("%s:%d", rc.Path, rc.OriginalLine)
+	default:
+		return fmt.Sprintf("%s:%d", rc.Path, rc.Line)
+	}
 }
 
 func NewClient() *Client {
-	return &Client{}
+	return &Client{exec: gh.Exec}
 }
 
 // SetDebug enables or disables debug output
@@ -61,8 +133,20 @@ func (c *Client) SetDebug(debug bool) {
 	c.debug = debug
 }
 
-// SetRepo sets the repository to use (format: "owner/repo")
+// SetRepo sets the repository to use (format: "[host/]owner/repo"). Without a host, the
+// repository is on github.com when another host was set before, else on gh's default host
+// rather than on the host of the git remote.
 func (c *Client) SetRepo(repo string) {
+	host, repo := splitRepo(repo)
+	switch {
+	case host != "":
+		c.SetHost(host)
+	case c.host != "" && !strings.EqualFold(c.host, defaultHost):
+		c.SetHost(defaultHost)
+	case c.host == "":
+		host, _ := auth.DefaultHost()
+		c.SetHost(host)
+	}
 	c.repo = repo
 }
 
@@ -85,32 +169,37 @@ type ThreadInfo struct {
 	Comments   []ThreadComment
 }
 
-// getReviewThreads fetches review threads with all comments using GraphQL
-func (c *Client) getReviewThreads(repo string, prNumber int) (map[int64]*ThreadInfo, error) {
-	parts := strings.Split(repo, "/")
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid repo format: %s", repo)
-	}
-	owner := parts[0]
-	name := parts[1]
-
-	c.debugLog("Fetching review threads for %s PR #%d", repo, prNumber)
-
-	query := fmt.Sprintf(`
-		query {
-			repository(owner: "%s", name: "%s") {
-				pullRequest(number: %d) {
-					reviewThreads(first: 100) {
-						nodes {
-							id
-							isResolved
-							comments(first: 50) {
-								nodes {
-									databaseId
-									body
-									url
-									author {
-										login
+// reviewThreadsQuery fetches one page of the review threads of a PR, each with its first page
+// of comments
+const reviewThreadsQuery = `
+	query($owner: String!, $name: String!, $number: Int!, $cursor: String) {
+		repository(owner: $owner, name: $name) {
+			pullRequest(number: $number) {
+				reviewThreads(first: 100, after: $cursor) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						id
+						isResolved
+						comments(first: 100) {
+							pageInfo {
+								hasNextPage
+								endCursor
+							}
+							nodes {
+								databaseId
+								body
+								url
+								createdAt
+								author {
+									login
+								}
+								reactionGroups {
+									content
+									reactors {
+										totalCount
 									}
 								}
 							}
@@ -119,83 +208,157 @@ func (c *Client) getReviewThreads(repo string, prNumber int) (map[int64]*ThreadI
 				}
 			}
 		}
-	`, owner, name, prNumber)
+	}
+`
+
+// threadCommentsQuery fetches the next page of comments of a review thread
+const threadCommentsQuery = `
+	query($id: ID!, $cursor: String) {
+		node(id: $id) {
+			... on PullRequestReviewThread {
+				comments(first: 100, after: $cursor) {
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+					nodes {
+						databaseId
+						body
+						url
+						createdAt
+						author {
+							login
+						}
+						reactionGroups {
+							content
+							reactors {
+								totalCount
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+`
 
-	c.debugLog("GraphQL query: %s", query)
+type graphQLPageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
 
-	stdOut, _, err := gh.Exec("api", "graphql", "-f", fmt.Sprintf("query=%s", query))
-	if err != nil {
-		c.debugLog("GraphQL query failed: %v", err)
+// next returns the cursor of the next page, or false on the last page
+func (p graphQLPageInfo) next() (string, bool) {
+	return p.EndCursor, p.HasNextPage && p.EndCursor != ""
+}
+
+type graphQLThreadComments struct {
+	PageInfo graphQLPageInfo `json:"pageInfo"`
+	Nodes    []struct {
+		DatabaseID int64     `json:"databaseId"`
+		Body       string    `json:"body"`
+		URL        string    `json:"url"`
+		CreatedAt  time.Time `json:"createdAt"`
+		Author     struct {
+			Login string `json:"login"`
+		} `json:"author"`
+		ReactionGroups graphQLReactionGroups `json:"reactionGroups"`
+	} `json:"nodes"`
+}
+
+func (tc graphQLThreadComments) threadComments() []ThreadComment {
+	comments := make([]ThreadComment, 0, len(tc.Nodes))
+	for _, comment := range tc.Nodes {
+		comments = append(comments, ThreadComment{
+			ID:        comment.DatabaseID,
+			Body:      comment.Body,
+			Author:    comment.Author.Login,
+			HTMLURL:   comment.URL,
+			CreatedAt: comment.CreatedAt,
+			Reactions: comment.ReactionGroups.reactions(),
+		})
+	}
+	return comments
+}
+
+// getReviewThreads fetches review threads with all comments using GraphQL, following the
+// cursors of the threads and of the comments of each thread
+func (c *Client) getReviewThreads(repo string, prNumber int) (map[int64]*ThreadInfo, error) {
+	if err := c.checkOnline(); err != nil {
 		return nil, err
 	}
 
-	c.debugLog("GraphQL response length: %d bytes", len(stdOut.Bytes()))
+	parts := strings.Split(repo, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid repo format: %s", repo)
+	}
+	owner := parts[0]
+	name := parts[1]
+
+	c.debugLog("Fetching review threads for %s PR #%d", repo, prNumber)
 
-	var result struct {
-		Data struct {
+	threads := make(map[int64]*ThreadInfo)
+	var cursor any
+	for page := 1; ; page++ {
+		var result struct {
 			Repository struct {
 				PullRequest struct {
 					ReviewThreads struct {
-						Nodes []struct {
-							ID         string `json:"id"`
-							IsResolved bool   `json:"isResolved"`
-							Comments   struct {
-								Nodes []struct {
-									DatabaseID int64  `json:"databaseId"`
-									Body       string `json:"body"`
-									URL        string `json:"url"`
-									Author     struct {
-										Login string `json:"login"`
-									} `json:"author"`
-								} `json:"nodes"`
-							} `json:"comments"`
+						PageInfo graphQLPageInfo `json:"pageInfo"`
+						Nodes    []struct {
+							ID         string                `json:"id"`
+							IsResolved bool                  `json:"isResolved"`
+							Comments   graphQLThreadComments `json:"comments"`
 						} `json:"nodes"`
 					} `json:"reviewThreads"`
 				} `json:"pullRequest"`
 			} `json:"repository"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(stdOut.Bytes(), &result); err != nil {
-		c.debugLog("Failed to parse GraphQL response: %v", err)
-		if c.debug {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Raw response: %s\n", stdOut.String())
 		}
-		return nil, fmt.Errorf("failed to parse GraphQL response: %w", err)
-	}
+		err := c.graphQL(reviewThreadsQuery, map[string]any{
+			"owner":  owner,
+			"name":   name,
+			"number": prNumber,
+			"cursor": cursor,
+		}, &result)
+		if err != nil {
+			return nil, err
+		}
 
-	c.debugLog("Found %d review threads", len(result.Data.Repository.PullRequest.ReviewThreads.Nodes))
+		reviewThreads := result.Repository.PullRequest.ReviewThreads
+		c.debugLog("Page %d: found %d review threads", page, len(reviewThreads.Nodes))
 
-	threads := make(map[int64]*ThreadInfo)
-	for i, thread := range result.Data.Repository.PullRequest.ReviewThreads.Nodes {
-		if len(thread.Comments.Nodes) == 0 {
-			c.debugLog("Thread %d: no comments, skipping", i)
-			continue
-		}
+		for i, thread := range reviewThreads.Nodes {
+			threadComments := thread.Comments.threadComments()
+			if more, ok := thread.Comments.PageInfo.next(); ok {
+				rest, err := c.getThreadComments(thread.ID, more)
+				if err != nil {
+					return nil, err
+				}
+				threadComments = append(threadComments, rest...)
+			}
 
-		// First comment is the key
-		firstCommentID := thread.Comments.Nodes[0].DatabaseID
-		c.debugLog("Thread %d: first comment ID=%d, resolved=%v, comments=%d",
-			i, firstCommentID, thread.IsResolved, len(thread.Comments.Nodes))
+			if len(threadComments) == 0 {
+				c.debugLog("Thread %d: no comments, skipping", i)
+				continue
+			}
 
-		// Collect all comments in the thread
-		var threadComments []ThreadComment
-		for j, comment := range thread.Comments.Nodes {
-			c.debugLog("  Comment %d: ID=%d, author=%s, body_len=%d",
-				j, comment.DatabaseID, comment.Author.Login, len(comment.Body))
-			threadComments = append(threadComments, ThreadComment{
-				ID:      comment.DatabaseID,
-				Body:    comment.Body,
-				Author:  comment.Author.Login,
-				HTMLURL: comment.URL,
-			})
+			// First comment is the key
+			firstCommentID := threadComments[0].ID
+			c.debugLog("Thread %d: first comment ID=%d, resolved=%v, comments=%d",
+				i, firstCommentID, thread.IsResolved, len(threadComments))
+
+			threads[firstCommentID] = &ThreadInfo{
+				ID:         thread.ID,
+				IsResolved: thread.IsResolved,
+				Comments:   threadComments,
+			}
 		}
 
-		threads[firstCommentID] = &ThreadInfo{
-			ID:         thread.ID,
-			IsResolved: thread.IsResolved,
-			Comments:   threadComments,
+		next, ok := reviewThreads.PageInfo.next()
+		if !ok {
+			break
 		}
+		cursor = next
 	}
 
 	c.debugLog("Returning %d threads", len(threads))
@@ -203,6 +366,32 @@ func (c *Client) getReviewThreads(repo string, prNumber int) (map[int64]*ThreadI
 	return threads, nil
 }
 
+// getThreadComments fetches the comments of a review thread from cursor on
+func (c *Client) getThreadComments(threadID, cursor string) ([]ThreadComment, error) {
+	var comments []ThreadComment
+	for {
+		c.debugLog("Fetching more comments of thread %s", threadID)
+
+		var result struct {
+			Node struct {
+				Comments graphQLThreadComments `json:"comments"`
+			} `json:"node"`
+		}
+		err := c.graphQL(threadCommentsQuery, map[string]any{"id": threadID, "cursor": cursor}, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		comments = append(comments, result.Node.Comments.threadComments()...)
+
+		next, ok := result.Node.Comments.PageInfo.next()
+		if !ok {
+			return comments, nil
+		}
+		cursor = next
+	}
+}
+
 // getReplyCommentIDs returns a set of comment IDs that are replies (not first comments in threads)
 func (c *Client) getReplyCommentIDs(threads map[int64]*ThreadInfo) map[int64]bool {
 	replyIDs := make(map[int64]bool)
@@ -221,25 +410,75 @@ func (c *Client) getRepo() (string, error) {
 		return c.repo, nil
 	}
 
-	stdOut, _, err := gh.Exec("repo", "view", "--json", "nameWithOwner", "--jq", ".nameWithOwner")
+	if c.offline {
+		if co := c.cachedCheckout(); co != nil {
+			host, repo := splitRepo(co.Repo)
+			if host == "" {
+				host = defaultHost
+			}
+			if c.host == "" {
+				c.host = host
+			}
+			c.repo = repo
+			return c.repo, nil
+		}
+		return "", fmt.Errorf("repository of this checkout is not cached; use --repo in offline mode")
+	}
+
+	stdOut, _, err := c.exec("repo", "view", "--json", "url")
 	if err != nil {
 		return "", fmt.Errorf("not in a GitHub repository (or no remote configured)")
 	}
 
-	c.repo = strings.TrimSpace(stdOut.String())
+	var view struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(stdOut.Bytes(), &view); err != nil {
+		return "", fmt.Errorf("failed to parse repository: %w", err)
+	}
+	host, repo, ok := repoFromURL(view.URL)
+	if !ok {
+		return "", fmt.Errorf("unexpected repository URL %q", view.URL)
+	}
+
+	if c.host == "" {
+		c.SetHost(host)
+	}
+	c.repo = repo
+	c.rememberCheckout(qualifiedRepo(host, repo), "", 0)
 	return c.repo, nil
 }
 
 func (c *Client) GetCurrentBranchPR() (int, error) {
-	stdOut, _, err := gh.Exec("pr", "view", "--json", "number", "--jq", ".number")
+	if c.offline {
+		if co := c.cachedCheckout(); co != nil {
+			if branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD"); err == nil && co.Branches[branch] > 0 {
+				return co.Branches[branch], nil
+			}
+		}
+		return 0, fmt.Errorf("PR of the current branch is not cached (use: gh prreview list <PR_NUMBER>)")
+	}
+
+	stdOut, _, err := c.exec("pr", "view", "--json", "number,url")
 	if err != nil {
 		return 0, fmt.Errorf("no PR found for current branch (use: gh prreview list <PR_NUMBER>)")
 	}
 
-	var prNumber int
-	if err := json.Unmarshal(stdOut.Bytes(), &prNumber); err != nil {
+	var pr struct {
+		Number int    `json:"number"`
+		URL    string `json:"url"`
+	}
+	if err := json.Unmarshal(stdOut.Bytes(), &pr); err != nil {
 		return 0, fmt.Errorf("failed to parse PR number: %w", err)
 	}
+	prNumber := pr.Number
+
+	// The URL has the repository gh resolved for this checkout
+	if host, repo, ok := repoFromURL(pr.URL); ok {
+		if branch, err := gitOutput("rev-parse", "--abbrev-ref", "HEAD"); err == nil {
+			c.rememberCheckout(qualifiedRepo(host, repo), branch, prNumber)
+		}
+	}
 
 	return prNumber, nil
 }
@@ -253,36 +492,77 @@ func (c *Client) DumpCommentsJSON(prNumber int, commentIDs []int64) (string, err
 	}
 
 	query := fmt.Sprintf("repos/%s/pulls/%d/comments", repo, prNumber)
-	stdOut, _, err := gh.Exec("api", query, "--paginate")
+	out, err := c.dumpRawJSON(query, commentIDs)
 	if err != nil {
 		return "", fmt.Errorf("failed to fetch review comments: %w", err)
 	}
+	return out, nil
+}
+
+// DumpReviewsJSON returns raw JSON for the selected review IDs. When reviewIDs is empty, all
+// reviews for the PR are returned.
+func (c *Client) DumpReviewsJSON(prNumber int, reviewIDs []int64) (string, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return "", err
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/%d/reviews", repo, prNumber)
+	out, err := c.dumpRawJSON(query, reviewIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+	return out, nil
+}
+
+// DumpIssueCommentsJSON returns raw JSON for the selected PR conversation comment IDs. When
+// commentIDs is empty, all conversation comments for the PR are returned.
+func (c *Client) DumpIssueCommentsJSON(prNumber int, commentIDs []int64) (string, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return "", err
+	}
+
+	query := fmt.Sprintf("repos/%s/issues/%d/comments", repo, prNumber)
+	out, err := c.dumpRawJSON(query, commentIDs)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch PR comments: %w", err)
+	}
+	return out, nil
+}
+
+// dumpRawJSON fetches a paginated REST list and returns the pretty-printed raw objects whose
+// "id" is in ids. When ids is empty, every object is returned.
+func (c *Client) dumpRawJSON(query string, ids []int64) (string, error) {
+	if err := c.checkOnline(); err != nil {
+		return "", err
+	}
 
-	var rawComments []json.RawMessage
-	if err := json.Unmarshal(stdOut.Bytes(), &rawComments); err != nil {
-		return "", fmt.Errorf("failed to parse comments: %w", err)
+	rawItems, err := restGetAll[json.RawMessage](c, query)
+	if err != nil {
+		return "", err
 	}
 
-	includeAll := len(commentIDs) == 0
-	wanted := make(map[int64]struct{}, len(commentIDs))
-	for _, id := range commentIDs {
+	includeAll := len(ids) == 0
+	wanted := make(map[int64]struct{}, len(ids))
+	for _, id := range ids {
 		wanted[id] = struct{}{}
 	}
 
 	selected := make([]json.RawMessage, 0)
-	for _, raw := range rawComments {
+	for _, raw := range rawItems {
 		if includeAll {
 			selected = append(selected, raw)
 			continue
 		}
 
-		var comment struct {
+		var item struct {
 			ID int64 `json:"id"`
 		}
-		if err := json.Unmarshal(raw, &comment); err != nil {
+		if err := json.Unmarshal(raw, &item); err != nil {
 			continue
 		}
-		if _, ok := wanted[comment.ID]; ok {
+		if _, ok := wanted[item.ID]; ok {
 			selected = append(selected, raw)
 		}
 	}
@@ -310,12 +590,171 @@ func (c *Client) DumpCommentsJSON(prNumber int, commentIDs []int64) (string, err
 	return pretty.String(), nil
 }
 
+// FetchReviews returns the reviews submitted on a pull request in chronological order
+func (c *Client) FetchReviews(prNumber int) ([]*Review, error) {
+	if err := c.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/%d/reviews", repo, prNumber)
+	rawReviews, err := restGetAll[rawReview](c, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch reviews: %w", err)
+	}
+
+	c.debugLog("Processing %d reviews from REST API", len(rawReviews))
+
+	reviews := make([]*Review, 0, len(rawReviews))
+	for _, raw := range rawReviews {
+		reviews = append(reviews, raw.review())
+	}
+
+	return reviews, nil
+}
+
+// FetchIssueComments returns the general PR conversation comments in chronological order
+func (c *Client) FetchIssueComments(prNumber int) ([]*IssueComment, error) {
+	if err := c.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	type rawIssueComment struct {
+		ID      int64  `json:"id"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		CreatedAt time.Time `json:"created_at"`
+	}
+
+	query := fmt.Sprintf("repos/%s/issues/%d/comments", repo, prNumber)
+	rawComments, err := restGetAll[rawIssueComment](c, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch PR comments: %w", err)
+	}
+
+	c.debugLog("Processing %d PR conversation comments from REST API", len(rawComments))
+
+	comments := make([]*IssueComment, 0, len(rawComments))
+	for _, raw := range rawComments {
+		comments = append(comments, &IssueComment{
+			ID:        raw.ID,
+			Body:      raw.Body,
+			Author:    raw.User.Login,
+			HTMLURL:   raw.HTMLURL,
+			CreatedAt: raw.CreatedAt,
+		})
+	}
+
+	return comments, nil
+}
+
+// ReviewCommentsChanged tells whether the review comments of a pull request may have changed
+// since the response that returned etag, using a conditional request that does not count
+// against the rate limit when nothing changed. It returns the new ETag to pass next time; an
+// empty etag always reports a change.
+func (c *Client) ReviewCommentsChanged(prNumber int, etag string) (bool, string, error) {
+	if err := c.checkOnline(); err != nil {
+		return false, "", err
+	}
+
+	repo, err := c.getRepo()
+	if err != nil {
+		return false, "", err
+	}
+
+	// The most recently updated comment is enough: any new or edited comment changes it
+	query := fmt.Sprintf("repos/%s/pulls/%d/comments?sort=updated&direction=desc&per_page=1", repo, prNumber)
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-None-Match", etag)
+	}
+
+	resp, err := c.restRequest(http.MethodGet, query, header, nil)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotModified {
+		c.debugLog("Conditional request for PR #%d: not modified", prNumber)
+		return false, etag, nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to check review comments: %w", err)
+	}
+	resp.Body.Close()
+
+	newETag := resp.Header.Get("ETag")
+	c.debugLog("Conditional request for PR #%d: status %d, ETag %s", prNumber, resp.StatusCode, newETag)
+	return true, newETag, nil
+}
+
+// ReplyToComment posts a reply in the review thread of a review comment
+func (c *Client) ReplyToComment(prNumber int, commentID int64, body string) (*ThreadComment, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("reply body is required")
+	}
+
+	if err := c.checkOnline(); err != nil {
+		return nil, err
+	}
+
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	c.debugLog("Replying to comment %d on PR #%d", commentID, prNumber)
+
+	var raw rawComment
+	query := fmt.Sprintf("repos/%s/pulls/%d/comments/%d/replies", repo, prNumber, commentID)
+	if err := c.restDo(http.MethodPost, query, map[string]string{"body": body}, &raw); err != nil {
+		return nil, fmt.Errorf("failed to reply to comment %d: %w", commentID, err)
+	}
+
+	return raw.threadComment(), nil
+}
+
+// rawReviewComment is a review comment as returned by the REST API
+type rawReviewComment struct {
+	ID        int64  `json:"id"`
+	ReviewID  int64  `json:"pull_request_review_id"`
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	StartLine int    `json:"start_line"`
+	Body      string `json:"body"`
+	DiffHunk  string `json:"diff_hunk"`
+	HTMLURL   string `json:"html_url"`
+	Side      string `json:"side"`
+	User      struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	OriginalLine      int          `json:"original_line"`
+	OriginalStartLine int          `json:"original_start_line"`
+	SubjectType       string       `json:"subject_type"`
+	CreatedAt         time.Time    `json:"created_at"`
+	UpdatedAt         time.Time    `json:"updated_at"`
+	Reactions         rawReactions `json:"reactions"`
+}
+
 func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 	repo, err := c.getRepo()
 	if err != nil {
 		return nil, err
 	}
 
+	if c.cache != nil {
+		return c.fetchReviewCommentsCached(repo, prNumber)
+	}
+
 	// First, get review threads with all comments using GraphQL
 	reviewThreads, err := c.getReviewThreads(repo, prNumber)
 	if err != nil {
@@ -323,34 +762,37 @@ func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 		reviewThreads = make(map[int64]*ThreadInfo)
 	}
 
-	// Fetch review comments using gh api
-	query := fmt.Sprintf("repos/%s/pulls/%d/comments", repo, prNumber)
-	stdOut, _, err := gh.Exec("api", query, "--paginate")
+	rawComments, err := c.fetchRawReviewComments(repo, prNumber, time.Time{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch review comments: %w", err)
+		return nil, err
 	}
 
-	var rawComments []struct {
-		ID        int64  `json:"id"`
-		Path      string `json:"path"`
-		Line      int    `json:"line"`
-		StartLine int    `json:"start_line"`
-		Body      string `json:"body"`
-		DiffHunk  string `json:"diff_hunk"`
-		HTMLURL   string `json:"html_url"`
-		Side      string `json:"side"`
-		User      struct {
-			Login string `json:"login"`
-		} `json:"user"`
-		OriginalLine      int    `json:"original_line"`
-		OriginalStartLine int    `json:"original_start_line"`
-		SubjectType       string `json:"subject_type"`
+	return c.buildReviewComments(rawComments, reviewThreads), nil
+}
+
+// fetchRawReviewComments fetches the review comments of a PR from the REST API, only the ones
+// updated after since when it is set
+func (c *Client) fetchRawReviewComments(repo string, prNumber int, since time.Time) ([]rawReviewComment, error) {
+	if err := c.checkOnline(); err != nil {
+		return nil, err
 	}
 
-	if err := json.Unmarshal(stdOut.Bytes(), &rawComments); err != nil {
-		return nil, fmt.Errorf("failed to parse review comments: %w", err)
+	query := fmt.Sprintf("repos/%s/pulls/%d/comments?per_page=100", repo, prNumber)
+	if !since.IsZero() {
+		query += "&since=" + since.UTC().Format(time.RFC3339)
 	}
+	rawComments, err := restGetAll[rawReviewComment](c, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch review comments: %w", err)
+	}
+
+	c.debugLog("Fetched %d review comments from REST API", len(rawComments))
+	return rawComments, nil
+}
 
+// buildReviewComments turns the REST review comments into threads, using the GraphQL thread
+// information for replies and resolution
+func (c *Client) buildReviewComments(rawComments []rawReviewComment, reviewThreads map[int64]*ThreadInfo) []*ReviewComment {
 	c.debugLog("Processing %d review comments from REST API", len(rawComments))
 
 	// Get set of reply comment IDs to skip
@@ -366,16 +808,19 @@ func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 
 		// Check if this comment has thread info
 		threadInfo := reviewThreads[raw.ID]
-		subjectType := raw.SubjectType
 		var threadComments []ThreadComment
 		var threadID string
+		resolved := false
+		reactions := raw.Reactions.reactions()
 
 		if threadInfo != nil {
 			c.debugLog("Comment %d: Found thread with %d total comments, resolved=%v",
 				raw.ID, len(threadInfo.Comments), threadInfo.IsResolved)
 			threadID = threadInfo.ID
-			if threadInfo.IsResolved {
-				subjectType = "resolved"
+			resolved = threadInfo.IsResolved
+			// Reactions do not change updated_at, so the cached REST copy may be stale
+			if len(threadInfo.Comments) > 0 && threadInfo.Comments[0].Reactions != nil {
+				reactions = threadInfo.Comments[0].Reactions
 			}
 			// Skip the first comment (it's the main review comment we're already showing)
 			if len(threadInfo.Comments) > 1 {
@@ -422,6 +867,7 @@ func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 		comment := &ReviewComment{
 			ID:                raw.ID,
 			ThreadID:          threadID,
+			ReviewID:          raw.ReviewID,
 			Path:              raw.Path,
 			Line:              raw.Line,
 			StartLine:         startLine,
@@ -433,9 +879,12 @@ func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 			OriginalLine:      raw.OriginalLine,
 			OriginalStartLine: originalStartLine,
 			OriginalEndLine:   originalEndLine,
-			SubjectType:       subjectType,
+			SubjectType:       raw.SubjectType,
 			HTMLURL:           raw.HTMLURL,
 			IsOutdated:        isOutdated,
+			Resolved:          resolved,
+			CreatedAt:         raw.CreatedAt,
+			Reactions:         reactions,
 			ThreadComments:    threadComments,
 		}
 
@@ -451,7 +900,7 @@ func (c *Client) FetchReviewComments(prNumber int) ([]*ReviewComment, error) {
 		comments = append(comments, comment)
 	}
 
-	return comments, nil
+	return comments
 }
 
 // calculateOriginalLines determines how many lines from the original file
@@ -494,47 +943,19 @@ func (c *Client) ResolveThread(threadID string) error {
 
 	c.debugLog("GraphQL mutation: %s (threadId=%s)", mutation, threadID)
 
-	stdOut, stdErr, err := gh.Exec("api", "graphql",
-		"-f", fmt.Sprintf("query=%s", mutation),
-		"-F", fmt.Sprintf("threadId=%s", threadID))
-	if err != nil {
-		c.debugLog("GraphQL mutation failed: %v", err)
-		if stdErr.Len() > 0 {
-			c.debugLog("Stderr: %s", stdErr.String())
-		}
-		return fmt.Errorf("failed to resolve thread: %w", err)
-	}
-
-	c.debugLog("GraphQL response length: %d bytes", len(stdOut.Bytes()))
-
-	// Parse response to verify it worked
 	var result struct {
-		Data struct {
-			ResolveReviewThread struct {
-				Thread struct {
-					ID         string `json:"id"`
-					IsResolved bool   `json:"isResolved"`
-				} `json:"thread"`
-			} `json:"resolveReviewThread"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal(stdOut.Bytes(), &result); err != nil {
-		c.debugLog("Failed to parse GraphQL response: %v", err)
-		if c.debug {
-			fmt.Fprintf(os.Stderr, "[DEBUG] Raw GraphQL response for ResolveThread: %s\n", stdOut.String())
-		}
-		return fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(result.Errors) > 0 {
-		return fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+		ResolveReviewThread struct {
+			Thread struct {
+				ID         string `json:"id"`
+				IsResolved bool   `json:"isResolved"`
+			} `json:"thread"`
+		} `json:"resolveReviewThread"`
+	}
+	if err := c.graphQL(mutation, map[string]any{"threadId": threadID}, &result); err != nil {
+		return fmt.Errorf("failed to resolve thread: %w", err)
 	}
 
-	if !result.Data.ResolveReviewThread.Thread.IsResolved {
+	if !result.ResolveReviewThread.Thread.IsResolved {
 		return fmt.Errorf("thread was not marked as resolved")
 	}
 
@@ -550,55 +971,30 @@ func (c *Client) UnresolveThread(threadID string) error {
 
 	c.debugLog("Unresolving thread with ID: %s", threadID)
 
-	mutation := fmt.Sprintf(`
-		mutation {
-			unresolveReviewThread(input: {threadId: "%s"}) {
-				thread {
-					id
-					isResolved
-				}
+	mutation := `mutation UnresolveThread($threadId: ID!) {
+		unresolveReviewThread(input: {threadId: $threadId}) {
+			thread {
+				id
+				isResolved
 			}
 		}
-	`, threadID)
-
-	c.debugLog("GraphQL mutation: %s", mutation)
-
-	stdOut, stdErr, err := gh.Exec("api", "graphql", "-f", fmt.Sprintf("query=%s", mutation))
-	if err != nil {
-		c.debugLog("GraphQL mutation failed: %v", err)
-		if stdErr.Len() > 0 {
-			c.debugLog("Stderr: %s", stdErr.String())
-		}
-		return fmt.Errorf("failed to unresolve thread: %w", err)
-	}
+	}`
 
-	c.debugLog("GraphQL response length: %d bytes", len(stdOut.Bytes()))
+	c.debugLog("GraphQL mutation: %s (threadId=%s)", mutation, threadID)
 
-	// Parse response to verify it worked
 	var result struct {
-		Data struct {
-			UnresolveReviewThread struct {
-				Thread struct {
-					ID         string `json:"id"`
-					IsResolved bool   `json:"isResolved"`
-				} `json:"thread"`
-			} `json:"unresolveReviewThread"`
-		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
-	}
-
-	if err := json.Unmarshal(stdOut.Bytes(), &result); err != nil {
-		c.debugLog("Failed to parse GraphQL response: %v", err)
-		return fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	if len(result.Errors) > 0 {
-		return fmt.Errorf("GraphQL error: %s", result.Errors[0].Message)
+		UnresolveReviewThread struct {
+			Thread struct {
+				ID         string `json:"id"`
+				IsResolved bool   `json:"isResolved"`
+			} `json:"thread"`
+		} `json:"unresolveReviewThread"`
+	}
+	if err := c.graphQL(mutation, map[string]any{"threadId": threadID}, &result); err != nil {
+		return fmt.Errorf("failed to unresolve thread: %w", err)
 	}
 
-	if result.Data.UnresolveReviewThread.Thread.IsResolved {
+	if result.UnresolveReviewThread.Thread.IsResolved {
 		return fmt.Errorf("thread was not marked as unresolved")
 	}
 
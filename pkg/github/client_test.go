@@ -0,0 +1,571 @@
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// redirectTransport sends every request to the test server instead of api.github.com
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// fakeGitHub is a test server answering with recorded fixtures. GraphQL requests are keyed as
+// "threads:<cursor>", "comments:<thread id>:<cursor>", "branch:<name>" or "addThread", prefixed
+// with the endpoint when it is not /graphql, and REST ones as "METHOD path?query".
+type fakeGitHub struct {
+	t        *testing.T
+	fixtures map[string]string
+	handlers map[string]http.HandlerFunc
+
+	mu       sync.Mutex
+	requests []string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if got := r.Header.Get("Authorization"); got != "token test-token" {
+		f.t.Errorf("Authorization = %q", got)
+	}
+
+	key := r.Method + " " + r.URL.RequestURI()
+	if strings.HasSuffix(r.URL.Path, "/graphql") {
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			f.t.Errorf("invalid GraphQL request: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cursor, _ := request.Variables["cursor"].(string)
+		if strings.Contains(request.Query, "reviewThreads") {
+			vars := request.Variables
+			if vars["owner"] != "owner" || vars["name"] != "repo" || vars["number"] != float64(42) {
+				f.t.Errorf("unexpected variables: %v", vars)
+			}
+			key = "threads:" + cursor
+		} else if strings.Contains(request.Query, "pullRequests(headRefName") {
+			key = fmt.Sprintf("branch:%v", request.Variables["branch"])
+		} else if strings.Contains(request.Query, "addPullRequestReviewThread") {
+			key = "addThread"
+		} else {
+			key = fmt.Sprintf("comments:%v:%s", request.Variables["id"], cursor)
+		}
+		if r.URL.Path != "/graphql" {
+			key = r.URL.Path + " " + key
+		}
+	}
+
+	f.mu.Lock()
+	f.requests = append(f.requests, key)
+	f.mu.Unlock()
+
+	if handler, ok := f.handlers[key]; ok {
+		handler(w, r)
+		return
+	}
+	fixture, ok := f.fixtures[key]
+	if !ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"message": "Not Found: %s"}`, key)
+		return
+	}
+	data, err := os.ReadFile(filepath.Join("testdata", fixture))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+func newTestClient(t *testing.T, fake *fakeGitHub) *Client {
+	t.Setenv("GH_HOST", "github.com")
+	fake.t = t
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	target, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := NewClient()
+	client.authToken = "test-token"
+	client.SetRepo("owner/repo")
+	client.SetTransport(redirectTransport{target: target})
+	return client
+}
+
+func commentIDs(comments []ThreadComment) []int64 {
+	ids := make([]int64, 0, len(comments))
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	return ids
+}
+
+func TestGetReviewThreadsPagination(t *testing.T) {
+	fake := &fakeGitHub{fixtures: map[string]string{
+		"threads:":                         "review_threads_page1.json",
+		"threads:Y3Vyc29yOnYyOpHOAAAAZA==": "review_threads_page2.json",
+		"comments:PRRT_kwDOAAABcs4AAAAB:Y3Vyc29yOnYyOpHOAAAAAg==": "thread_comments_page2.json",
+		"comments:PRRT_kwDOAAABcs4AAAAB:Y3Vyc29yOnYyOpHOAAAAAw==": "thread_comments_page3.json",
+	}}
+	client := newTestClient(t, fake)
+
+	threads, err := client.getReviewThreads("owner/repo", 42)
+	if err != nil {
+		t.Fatalf("getReviewThreads() error = %v", err)
+	}
+
+	wantRequests := []string{
+		"threads:",
+		"comments:PRRT_kwDOAAABcs4AAAAB:Y3Vyc29yOnYyOpHOAAAAAg==",
+		"comments:PRRT_kwDOAAABcs4AAAAB:Y3Vyc29yOnYyOpHOAAAAAw==",
+		"threads:Y3Vyc29yOnYyOpHOAAAAZA==",
+	}
+	if !reflect.DeepEqual(fake.requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", fake.requests, wantRequests)
+	}
+
+	tests := []struct {
+		firstID  int64
+		threadID string
+		resolved bool
+		comments []int64
+	}{
+		{firstID: 1001, threadID: "PRRT_kwDOAAABcs4AAAAB", comments: []int64{1001, 1002, 1003, 1004}},
+		{firstID: 2001, threadID: "PRRT_kwDOAAABcs4AAAAC", resolved: true, comments: []int64{2001}},
+		{firstID: 3001, threadID: "PRRT_kwDOAAABcs4AAAAD", resolved: true, comments: []int64{3001}},
+	}
+	if len(threads) != len(tests) {
+		t.Errorf("got %d threads, want %d (the thread without comments is skipped)", len(threads), len(tests))
+	}
+	for _, tt := range tests {
+		thread, ok := threads[tt.firstID]
+		if !ok {
+			t.Errorf("thread %d is missing", tt.firstID)
+			continue
+		}
+		if thread.ID != tt.threadID || thread.IsResolved != tt.resolved {
+			t.Errorf("thread %d = %s resolved=%v, want %s resolved=%v", tt.firstID, thread.ID, thread.IsResolved, tt.threadID, tt.resolved)
+		}
+		if got := commentIDs(thread.Comments); !reflect.DeepEqual(got, tt.comments) {
+			t.Errorf("thread %d comments = %v, want %v", tt.firstID, got, tt.comments)
+		}
+	}
+
+	last := threads[1001].Comments[3]
+	if last.Author != "bob" || last.Body != "Done." || last.CreatedAt.IsZero() {
+		t.Errorf("comment from the last page = %+v", last)
+	}
+
+	replies := client.getReplyCommentIDs(threads)
+	for _, id := range []int64{1002, 1003, 1004} {
+		if !replies[id] {
+			t.Errorf("comment %d should be a reply", id)
+		}
+	}
+}
+
+func TestGetReviewThreadsErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixtures map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "GraphQL error",
+			fixtures: map[string]string{"threads:": "graphql_error.json"},
+			wantErr:  "Could not resolve to a PullRequest",
+		},
+		{
+			name:     "failing second page",
+			fixtures: map[string]string{"threads:": "review_threads_page1.json"},
+			wantErr:  "HTTP 404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := newTestClient(t, &fakeGitHub{fixtures: tt.fixtures})
+			_, err := client.getReviewThreads("owner/repo", 42)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("getReviewThreads() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFetchReviewComments(t *testing.T) {
+	fake := &fakeGitHub{
+		fixtures: map[string]string{
+			"threads:": "review_threads_page2.json",
+			"GET /repos/owner/repo/pulls/42/comments?per_page=100&page=2": "review_comments_page2.json",
+		},
+		handlers: map[string]http.HandlerFunc{
+			"GET /repos/owner/repo/pulls/42/comments?per_page=100": func(w http.ResponseWriter, r *http.Request) {
+				next := "http://" + r.Host + "/repos/owner/repo/pulls/42/comments?per_page=100&page=2"
+				w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next", <%s>; rel="last"`, next, next))
+				data, _ := os.ReadFile(filepath.Join("testdata", "review_comments_page1.json"))
+				w.Write(data)
+			},
+		},
+	}
+	client := newTestClient(t, fake)
+
+	comments, err := client.FetchReviewComments(42)
+	if err != nil {
+		t.Fatalf("FetchReviewComments() error = %v", err)
+	}
+
+	// 1002 is kept since page 2 of the threads does not list it as a reply
+	var ids []int64
+	for _, comment := range comments {
+		ids = append(ids, comment.ID)
+	}
+	if want := []int64{1001, 1002, 2001}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("comment IDs = %v, want %v", ids, want)
+	}
+
+	first := comments[0]
+	if !first.HasSuggestion || first.StartLine != 10 || first.EndLine != 12 || first.Author != "alice" || first.ReviewID != 501 {
+		t.Errorf("first comment = %+v", first)
+	}
+	if !comments[2].IsFileLevel() {
+		t.Errorf("comment 2001 should be file-level")
+	}
+}
+
+func TestReviewCommentsChanged(t *testing.T) {
+	const query = "GET /repos/owner/repo/pulls/42/comments?sort=updated&direction=desc&per_page=1"
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		query: func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte("[]"))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	changed, etag, err := client.ReviewCommentsChanged(42, "")
+	if err != nil || !changed || etag != `"v1"` {
+		t.Fatalf("first ReviewCommentsChanged() = %v, %q, %v", changed, etag, err)
+	}
+
+	changed, etag, err = client.ReviewCommentsChanged(42, etag)
+	if err != nil || changed || etag != `"v1"` {
+		t.Errorf("ReviewCommentsChanged() when not modified = %v, %q, %v", changed, etag, err)
+	}
+}
+
+func TestReplyToComment(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"POST /repos/owner/repo/pulls/42/comments/1001/replies": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["body"] != "Fixed, thanks!" {
+				t.Errorf("reply body = %v, %v", body, err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id": 1005, "body": "Fixed, thanks!", "user": {"login": "me"}, "html_url": "https://github.com/owner/repo/pull/42#discussion_r1005"}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	reply, err := client.ReplyToComment(42, 1001, "Fixed, thanks!")
+	if err != nil {
+		t.Fatalf("ReplyToComment() error = %v", err)
+	}
+	if reply.ID != 1005 || reply.Author != "me" {
+		t.Errorf("ReplyToComment() = %+v", reply)
+	}
+}
+
+func TestHTTPErrorsAreStructured(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"GET /repos/owner/repo/pulls/42/reviews": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Resource not accessible by integration"}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	_, err := client.FetchReviews(42)
+	var httpErr *api.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("FetchReviews() error = %v, want an *api.HTTPError with status 403", err)
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("a plain 403 should not be retried, got %d requests", len(fake.requests))
+	}
+}
+
+func TestRateLimitedRequestsAreRetried(t *testing.T) {
+	attempts := 0
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"GET /repos/owner/repo/issues/42/comments": func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.Header().Set("Content-Type", "application/json")
+			if attempts == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusForbidden)
+				w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+				return
+			}
+			w.Write([]byte(`[{"id": 7, "body": "LGTM", "user": {"login": "carol"}}]`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	comments, err := client.FetchIssueComments(42)
+	if err != nil {
+		t.Fatalf("FetchIssueComments() error = %v", err)
+	}
+	if attempts != 2 || len(comments) != 1 || comments[0].Author != "carol" {
+		t.Errorf("got %d attempts and %+v", attempts, comments)
+	}
+}
+
+// stubTransport returns the queued responses in order
+type stubTransport struct {
+	responses []*http.Response
+	requests  int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil && req.Body != http.NoBody {
+		body, _ := io.ReadAll(req.Body)
+		if string(body) != "payload" {
+			return nil, fmt.Errorf("attempt %d got body %q", s.requests+1, body)
+		}
+	}
+	resp := s.responses[s.requests]
+	s.requests++
+	resp.Request = req
+	return resp, nil
+}
+
+func stubResponse(status int, header map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader(""))}
+	for name, value := range header {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	reset := fmt.Sprint(now.Add(20 * time.Second).Unix())
+	farReset := fmt.Sprint(now.Add(time.Hour).Unix())
+
+	tests := []struct {
+		name       string
+		method     string
+		responses  []*http.Response
+		wantStatus int
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "primary rate limit waits for the reset",
+			method:     http.MethodPost,
+			responses:  []*http.Response{stubResponse(403, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset}), stubResponse(200, nil)},
+			wantStatus: 200,
+			wantWaits:  []time.Duration{21 * time.Second},
+		},
+		{
+			name:       "reset too far away is not waited for",
+			method:     http.MethodGet,
+			responses:  []*http.Response{stubResponse(403, map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": farReset})},
+			wantStatus: 403,
+		},
+		{
+			name:       "secondary rate limit",
+			method:     http.MethodGet,
+			responses:  []*http.Response{stubResponse(429, map[string]string{"Retry-After": "3"}), stubResponse(200, nil)},
+			wantStatus: 200,
+			wantWaits:  []time.Duration{3 * time.Second},
+		},
+		{
+			name:       "server errors back off on GET",
+			method:     http.MethodGet,
+			responses:  []*http.Response{stubResponse(502, nil), stubResponse(503, nil), stubResponse(200, nil)},
+			wantStatus: 200,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:       "server errors are not retried on POST",
+			method:     http.MethodPost,
+			responses:  []*http.Response{stubResponse(502, nil)},
+			wantStatus: 502,
+		},
+		{
+			name:   "gives up after the last retry",
+			method: http.MethodGet,
+			responses: []*http.Response{
+				stubResponse(503, nil), stubResponse(503, nil), stubResponse(503, nil), stubResponse(503, nil),
+			},
+			wantStatus: 503,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second, 4 * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &stubTransport{responses: tt.responses}
+			var waits []time.Duration
+			transport := newRetryTransport(stub, t.Logf)
+			transport.now = func() time.Time { return now }
+			transport.sleep = func(ctx context.Context, d time.Duration) error {
+				waits = append(waits, d)
+				return nil
+			}
+
+			var body io.Reader
+			if tt.method == http.MethodPost {
+				body = strings.NewReader("payload")
+			}
+			req, err := http.NewRequest(tt.method, "https://api.github.com/graphql", body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatalf("RoundTrip() error = %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if !reflect.DeepEqual(waits, tt.wantWaits) {
+				t.Errorf("waits = %v, want %v", waits, tt.wantWaits)
+			}
+			if stub.requests != len(tt.responses) {
+				t.Errorf("sent %d requests, want %d", stub.requests, len(tt.responses))
+			}
+		})
+	}
+}
+
+func TestSetRepo(t *testing.T) {
+	t.Setenv("GH_HOST", "github.com")
+
+	tests := []struct {
+		name     string
+		hostname string
+		repo     string
+		wantHost string
+		wantRepo string
+		wantURL  string
+	}{
+		{
+			name:     "default host",
+			repo:     "owner/repo",
+			wantHost: "github.com",
+			wantRepo: "owner/repo",
+			wantURL:  "https://github.com/owner/repo",
+		},
+		{
+			name:     "host in the repository",
+			repo:     "GHE.example.com/owner/repo",
+			wantHost: "ghe.example.com",
+			wantRepo: "owner/repo",
+			wantURL:  "https://ghe.example.com/owner/repo",
+		},
+		{
+			name:     "hostname flag",
+			hostname: "ghe.example.com",
+			repo:     "owner/repo",
+			wantHost: "ghe.example.com",
+			wantRepo: "owner/repo",
+			wantURL:  "https://ghe.example.com/owner/repo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := NewClient()
+			client.SetRepo(tt.repo)
+			if tt.hostname != "" {
+				client.SetHost(tt.hostname)
+			}
+
+			repo, err := client.GetRepo()
+			if err != nil {
+				t.Fatal(err)
+			}
+			repoURL, _ := client.RepoURL()
+			if client.Host() != tt.wantHost || repo != tt.wantRepo || repoURL != tt.wantURL {
+				t.Errorf("got host %q, repo %q, URL %q", client.Host(), repo, repoURL)
+			}
+		})
+	}
+}
+
+func TestEnterpriseEndpoints(t *testing.T) {
+	fake := &fakeGitHub{fixtures: map[string]string{
+		"GET /api/v3/repos/owner/repo/pulls/42/comments?per_page=100": "review_comments_page2.json",
+		"/api/graphql threads:": "review_threads_page2.json",
+	}}
+	client := newTestClient(t, fake)
+	client.SetRepo("ghe.example.com/owner/repo")
+
+	comments, err := client.FetchReviewComments(42)
+	if err != nil {
+		t.Fatalf("FetchReviewComments() error = %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != 2001 {
+		t.Errorf("FetchReviewComments() = %+v", comments)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("requests = %v", fake.requests)
+	}
+}
+
+func TestRepoFromURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		wantHost string
+		wantRepo string
+		wantOK   bool
+	}{
+		{url: "https://github.com/owner/repo", wantHost: "github.com", wantRepo: "owner/repo", wantOK: true},
+		{url: "https://ghe.example.com/owner/repo/pull/42", wantHost: "ghe.example.com", wantRepo: "owner/repo", wantOK: true},
+		{url: "https://github.com/owner", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		host, repo, ok := repoFromURL(tt.url)
+		if host != tt.wantHost || repo != tt.wantRepo || ok != tt.wantOK {
+			t.Errorf("repoFromURL(%q) = %q, %q, %v", tt.url, host, repo, ok)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// rawComment is the part of a REST review comment needed to show or edit it
+type rawComment struct {
+	ID      int64  `json:"id"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (r rawComment) threadComment() *ThreadComment {
+	return &ThreadComment{
+		ID:        r.ID,
+		Body:      r.Body,
+		Author:    r.User.Login,
+		HTMLURL:   r.HTMLURL,
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// CurrentUser returns the login of the authenticated user
+func (c *Client) CurrentUser() (string, error) {
+	if c.login != "" {
+		return c.login, nil
+	}
+
+	var user struct {
+		Login string `json:"login"`
+	}
+	if err := c.restDo(http.MethodGet, "user", nil, &user); err != nil {
+		return "", fmt.Errorf("failed to look up the authenticated user: %w", err)
+	}
+	c.login = user.Login
+	return c.login, nil
+}
+
+// GetReviewComment returns a review comment, a thread's first comment or a reply
+func (c *Client) GetReviewComment(commentID int64) (*ThreadComment, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw rawComment
+	query := fmt.Sprintf("repos/%s/pulls/comments/%d", repo, commentID)
+	if err := c.restDo(http.MethodGet, query, nil, &raw); err != nil {
+		return nil, fmt.Errorf("failed to fetch comment %d: %w", commentID, err)
+	}
+	return raw.threadComment(), nil
+}
+
+// EditReviewComment replaces the body of one of our review comments
+func (c *Client) EditReviewComment(commentID int64, body string) (*ThreadComment, error) {
+	if strings.TrimSpace(body) == "" {
+		return nil, fmt.Errorf("comment body is required")
+	}
+
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	c.debugLog("Editing comment %d", commentID)
+
+	var raw rawComment
+	query := fmt.Sprintf("repos/%s/pulls/comments/%d", repo, commentID)
+	if err := c.restDo(http.MethodPatch, query, map[string]string{"body": body}, &raw); err != nil {
+		return nil, fmt.Errorf("failed to edit comment %d: %w", commentID, err)
+	}
+	return raw.threadComment(), nil
+}
+
+// DeleteReviewComment deletes one of our review comments. Deleting the first comment of a
+// thread deletes the thread when it has no replies.
+func (c *Client) DeleteReviewComment(commentID int64) error {
+	repo, err := c.getRepo()
+	if err != nil {
+		return err
+	}
+
+	c.debugLog("Deleting comment %d", commentID)
+
+	query := fmt.Sprintf("repos/%s/pulls/comments/%d", repo, commentID)
+	if err := c.restDo(http.MethodDelete, query, nil, nil); err != nil {
+		return fmt.Errorf("failed to delete comment %d: %w", commentID, err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCurrentUserIsCached(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"GET /user": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"login": "me"}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	for range 2 {
+		login, err := client.CurrentUser()
+		if err != nil || login != "me" {
+			t.Fatalf("CurrentUser() = %q, %v", login, err)
+		}
+	}
+	if len(fake.requests) != 1 {
+		t.Errorf("requests = %v, want a single lookup", fake.requests)
+	}
+}
+
+func TestEditAndDeleteReviewComment(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"GET /repos/owner/repo/pulls/comments/1005": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": 1005, "body": "Fixde", "user": {"login": "me"}}`))
+		},
+		"PATCH /repos/owner/repo/pulls/comments/1005": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["body"] != "Fixed" {
+				t.Errorf("request = %v, %v", body, err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": 1005, "body": "Fixed", "user": {"login": "me"}}`))
+		},
+		"DELETE /repos/owner/repo/pulls/comments/1005": func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	comment, err := client.GetReviewComment(1005)
+	if err != nil || comment.Body != "Fixde" || comment.Author != "me" {
+		t.Fatalf("GetReviewComment() = %+v, %v", comment, err)
+	}
+	edited, err := client.EditReviewComment(1005, "Fixed")
+	if err != nil || edited.Body != "Fixed" {
+		t.Fatalf("EditReviewComment() = %+v, %v", edited, err)
+	}
+	if _, err := client.EditReviewComment(1005, "  "); err == nil {
+		t.Error("EditReviewComment() with an empty body should fail")
+	}
+	if err := client.DeleteReviewComment(1005); err != nil {
+		t.Fatalf("DeleteReviewComment() error = %v", err)
+	}
+
+	want := []string{
+		"GET /repos/owner/repo/pulls/comments/1005",
+		"PATCH /repos/owner/repo/pulls/comments/1005",
+		"DELETE /repos/owner/repo/pulls/comments/1005",
+	}
+	if !reflect.DeepEqual(fake.requests, want) {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+}
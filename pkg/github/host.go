@@ -0,0 +1,86 @@
+package github
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/cli/go-gh/v2/pkg/repository"
+)
+
+// defaultHost is the host of github.com repositories, left out of qualified repository names
+const defaultHost = "github.com"
+
+// SetHost sets the GitHub host to talk to, e.g. a GitHub Enterprise Server hostname
+func (c *Client) SetHost(host string) {
+	c.host = strings.ToLower(strings.TrimSuffix(host, "/"))
+	c.httpClient = nil
+	c.graphQLClient = nil
+	c.login = ""
+}
+
+// Host returns the GitHub host of the repository: the one set with SetHost or in a
+// HOST/OWNER/REPO repository, else $GH_HOST, else the host of the git remote of the
+// checkout, else gh's default host
+func (c *Client) Host() string {
+	if c.host == "" {
+		c.host = detectHost()
+		c.debugLog("Using host %s", c.host)
+	}
+	return c.host
+}
+
+func detectHost() string {
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return host
+	}
+	if repo, err := repository.Current(); err == nil && repo.Host != "" {
+		return repo.Host
+	}
+	host, _ := auth.DefaultHost()
+	return host
+}
+
+// RepoURL returns the web URL of the repository
+func (c *Client) RepoURL() (string, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%s/%s", c.Host(), repo), nil
+}
+
+// QualifiedRepo returns the repository as [HOST/]OWNER/REPO, which SetRepo accepts
+func (c *Client) QualifiedRepo() (string, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return "", err
+	}
+	return qualifiedRepo(c.Host(), repo), nil
+}
+
+// qualifiedRepo returns HOST/OWNER/REPO, or OWNER/REPO on github.com
+func qualifiedRepo(host, repo string) string {
+	if host == "" || strings.EqualFold(host, defaultHost) {
+		return repo
+	}
+	return host + "/" + repo
+}
+
+// splitRepo splits [HOST/]OWNER/REPO into the host, empty when not given, and OWNER/REPO
+func splitRepo(repo string) (string, string) {
+	if parts := strings.Split(repo, "/"); len(parts) == 3 {
+		return parts[0], parts[1] + "/" + parts[2]
+	}
+	return "", repo
+}
+
+// repoFromURL returns the host and OWNER/REPO of a web URL like https://HOST/OWNER/REPO/pull/N
+func repoFromURL(url string) (string, string, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://"), "/")
+	if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1] + "/" + parts[2], true
+}
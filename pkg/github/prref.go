@@ -0,0 +1,183 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// PRRef is a pull request as given on the command line: a number, a PR URL,
+// [HOST/]OWNER/REPO#NUMBER or a branch name. Host and Repo are only set when the reference
+// names the repository, and CommentID when a URL points at a review comment.
+type PRRef struct {
+	Host      string
+	Repo      string
+	Number    int
+	Branch    string
+	CommentID int64
+}
+
+// ParsePRRef parses a pull request reference. Anything that is not a number, a URL or a
+// repository reference is taken as a branch name.
+func ParsePRRef(s string) (PRRef, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return PRRef{}, fmt.Errorf("empty pull request reference")
+	}
+
+	if number, err := strconv.Atoi(strings.TrimPrefix(s, "#")); err == nil {
+		if number <= 0 {
+			return PRRef{}, fmt.Errorf("invalid PR number: %s", s)
+		}
+		return PRRef{Number: number}, nil
+	}
+
+	if strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://") {
+		return parsePRURL(s)
+	}
+
+	if repo, number, ok := strings.Cut(s, "#"); ok {
+		host, repo := splitRepo(repo)
+		n, err := strconv.Atoi(number)
+		if err != nil || n <= 0 || strings.Count(repo, "/") != 1 || strings.HasPrefix(repo, "/") || strings.HasSuffix(repo, "/") {
+			return PRRef{}, fmt.Errorf("invalid pull request reference %q (expected [HOST/]OWNER/REPO#NUMBER)", s)
+		}
+		return PRRef{Host: host, Repo: repo, Number: n}, nil
+	}
+
+	if strings.ContainsAny(s, " \t~^:?*[\\") && !isOwnerBranch(s) {
+		return PRRef{}, fmt.Errorf("invalid pull request reference %q", s)
+	}
+	return PRRef{Branch: s}, nil
+}
+
+// isOwnerBranch tells whether s is OWNER:BRANCH, the branch of a fork
+func isOwnerBranch(s string) bool {
+	owner, branch, ok := strings.Cut(s, ":")
+	return ok && owner != "" && branch != "" && !strings.ContainsAny(owner+branch, " \t~^:?*[\\")
+}
+
+// parsePRURL parses https://HOST/OWNER/REPO/pull/NUMBER[/files][#discussion_rID]
+func parsePRURL(s string) (PRRef, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return PRRef{}, fmt.Errorf("invalid pull request URL %q: %w", s, err)
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" {
+		return PRRef{}, fmt.Errorf("not a pull request URL: %s", s)
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return PRRef{}, fmt.Errorf("not a pull request URL: %s", s)
+	}
+
+	ref := PRRef{
+		Host:   strings.ToLower(u.Hostname()),
+		Repo:   parts[0] + "/" + parts[1],
+		Number: number,
+	}
+
+	// Review comments are linked as #discussion_r<ID> (or #r<ID> from the files tab)
+	if fragment := u.Fragment; fragment != "" {
+		id := strings.TrimPrefix(strings.TrimPrefix(fragment, "discussion_"), "r")
+		if commentID, err := strconv.ParseInt(id, 10, 64); err == nil && id != fragment {
+			ref.CommentID = commentID
+		}
+	}
+	return ref, nil
+}
+
+// QualifiedRepo returns the repository of the reference as [HOST/]OWNER/REPO, or "" when the
+// reference does not name one
+func (r PRRef) QualifiedRepo() string {
+	if r.Repo == "" {
+		return ""
+	}
+	return qualifiedRepo(r.Host, r.Repo)
+}
+
+// ResolvePRRef returns the number of the referenced pull request. A reference naming a
+// repository makes it the client's repository, on the host of the reference when it has one
+// (URLs always do, github.com included), else on the host already set; a branch is looked
+// up among the open PRs.
+func (c *Client) ResolvePRRef(ref PRRef) (int, error) {
+	switch {
+	case ref.Repo != "" && ref.Host != "":
+		c.SetRepo(ref.Host + "/" + ref.Repo)
+	case ref.Repo != "" && c.host != "":
+		c.SetRepo(c.host + "/" + ref.Repo)
+	case ref.Repo != "":
+		c.SetRepo(ref.Repo)
+	}
+	if ref.Branch == "" {
+		return ref.Number, nil
+	}
+	return c.FindPRForBranch(ref.Branch)
+}
+
+// FindPRForBranch returns the open pull request whose head is branch, given as BRANCH or
+// OWNER:BRANCH for the branch of a fork
+func (c *Client) FindPRForBranch(branch string) (int, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return 0, err
+	}
+	owner, name, _ := strings.Cut(repo, "/")
+
+	headOwner := ""
+	if o, b, ok := strings.Cut(branch, ":"); ok {
+		headOwner, branch = o, b
+	}
+
+	query := `query($owner: String!, $name: String!, $branch: String!) {
+		repository(owner: $owner, name: $name) {
+			pullRequests(headRefName: $branch, states: OPEN, first: 20, orderBy: {field: UPDATED_AT, direction: DESC}) {
+				nodes {
+					number
+					headRepositoryOwner {
+						login
+					}
+				}
+			}
+		}
+	}`
+
+	var result struct {
+		Repository struct {
+			PullRequests struct {
+				Nodes []struct {
+					Number              int `json:"number"`
+					HeadRepositoryOwner struct {
+						Login string `json:"login"`
+					} `json:"headRepositoryOwner"`
+				} `json:"nodes"`
+			} `json:"pullRequests"`
+		} `json:"repository"`
+	}
+	if err := c.graphQL(query, map[string]any{"owner": owner, "name": name, "branch": branch}, &result); err != nil {
+		return 0, fmt.Errorf("failed to look up the PR of branch %s: %w", branch, err)
+	}
+
+	var numbers []string
+	number := 0
+	for _, pr := range result.Repository.PullRequests.Nodes {
+		if headOwner != "" && !strings.EqualFold(pr.HeadRepositoryOwner.Login, headOwner) {
+			continue
+		}
+		number = pr.Number
+		numbers = append(numbers, fmt.Sprintf("#%d (%s)", pr.Number, pr.HeadRepositoryOwner.Login))
+	}
+
+	switch len(numbers) {
+	case 0:
+		return 0, fmt.Errorf("no open PR found for branch %s in %s", branch, repo)
+	case 1:
+		c.debugLog("Branch %s is PR #%d", branch, number)
+		return number, nil
+	default:
+		return 0, fmt.Errorf("several open PRs for branch %s: %s; use OWNER:BRANCH or the PR number", branch, strings.Join(numbers, ", "))
+	}
+}
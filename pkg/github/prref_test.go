@@ -0,0 +1,167 @@
+package github
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePRRef(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    PRRef
+		wantErr bool
+	}{
+		{input: "42", want: PRRef{Number: 42}},
+		{input: "#42", want: PRRef{Number: 42}},
+		{input: "0", wantErr: true},
+		{input: "owner/repo#42", want: PRRef{Repo: "owner/repo", Number: 42}},
+		{input: "ghe.example.com/owner/repo#42", want: PRRef{Host: "ghe.example.com", Repo: "owner/repo", Number: 42}},
+		{input: "owner/repo#abc", wantErr: true},
+		{input: "repo#42", wantErr: true},
+		{
+			input: "https://github.com/owner/repo/pull/42",
+			want:  PRRef{Host: "github.com", Repo: "owner/repo", Number: 42},
+		},
+		{
+			input: "https://GHE.example.com/owner/repo/pull/42/files",
+			want:  PRRef{Host: "ghe.example.com", Repo: "owner/repo", Number: 42},
+		},
+		{
+			input: "https://github.com/owner/repo/pull/42#discussion_r1001",
+			want:  PRRef{Host: "github.com", Repo: "owner/repo", Number: 42, CommentID: 1001},
+		},
+		{
+			input: "https://github.com/owner/repo/pull/42/files#r1001",
+			want:  PRRef{Host: "github.com", Repo: "owner/repo", Number: 42, CommentID: 1001},
+		},
+		{
+			input: "https://github.com/owner/repo/pull/42#issuecomment-7",
+			want:  PRRef{Host: "github.com", Repo: "owner/repo", Number: 42},
+		},
+		{input: "https://github.com/owner/repo/issues/42", wantErr: true},
+		{input: "feature/login", want: PRRef{Branch: "feature/login"}},
+		{input: "contributor:fix-typo", want: PRRef{Branch: "contributor:fix-typo"}},
+		{input: "not a branch", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParsePRRef(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePRRef() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePRRef() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindPRForBranch(t *testing.T) {
+	tests := []struct {
+		branch  string
+		want    int
+		wantErr string
+	}{
+		{branch: "owner:fix", want: 42},
+		{branch: "contributor:fix", want: 57},
+		{branch: "fix", wantErr: "several open PRs"},
+		{branch: "someone:fix", wantErr: "no open PR found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			fake := &fakeGitHub{fixtures: map[string]string{"branch:fix": "branch_pull_requests.json"}}
+			client := newTestClient(t, fake)
+
+			got, err := client.FindPRForBranch(tt.branch)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("FindPRForBranch() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("FindPRForBranch() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePRRefSetsRepo(t *testing.T) {
+	t.Setenv("GH_HOST", "github.com")
+	client := NewClient()
+	client.SetRepo("owner/repo")
+
+	ref, err := ParsePRRef("https://ghe.example.com/other/project/pull/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	number, err := client.ResolvePRRef(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := client.GetRepo()
+	if number != 7 || repo != "other/project" || client.Host() != "ghe.example.com" {
+		t.Errorf("got PR %d of %s on %s", number, repo, client.Host())
+	}
+}
+
+func TestResolvePRRefKeepsGitHubHost(t *testing.T) {
+	t.Setenv("GH_HOST", "ghe.example.com")
+	client := NewClient()
+	client.SetRepo("ghe.example.com/owner/repo")
+
+	tests := []struct {
+		ref      string
+		wantRepo string
+		wantHost string
+	}{
+		{ref: "https://github.com/other/project/pull/7", wantRepo: "other/project", wantHost: "github.com"},
+		{ref: "github.com/other/project#7", wantRepo: "other/project", wantHost: "github.com"},
+		// Without a host, the reference stays on the host already set
+		{ref: "other/project#7", wantRepo: "other/project", wantHost: "ghe.example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			client.SetHost("ghe.example.com")
+			ref, err := ParsePRRef(tt.ref)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.ResolvePRRef(ref); err != nil {
+				t.Fatal(err)
+			}
+			repo, _ := client.GetRepo()
+			if repo != tt.wantRepo || client.Host() != tt.wantHost {
+				t.Errorf("got %s on %s, want %s on %s", repo, client.Host(), tt.wantRepo, tt.wantHost)
+			}
+		})
+	}
+}
+
+func TestResolvePRRefCommentURL(t *testing.T) {
+	t.Setenv("GH_HOST", "ghe.example.com")
+	client := NewClient()
+	client.SetRepo("ghe.example.com/owner/repo")
+
+	// As given to edit, delete and react from a GitHub Enterprise Server checkout
+	ref, err := ParsePRRef("https://github.com/other/project/pull/7#discussion_r123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.CommentID != 123 {
+		t.Fatalf("CommentID = %d, want 123", ref.CommentID)
+	}
+	if _, err := client.ResolvePRRef(ref); err != nil {
+		t.Fatal(err)
+	}
+	repo, _ := client.GetRepo()
+	if repo != "other/project" || client.Host() != "github.com" {
+		t.Errorf("got %s on %s, want other/project on github.com", repo, client.Host())
+	}
+}
@@ -0,0 +1,184 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Reactions counts the emoji reactions of a comment by their REST content name (+1, -1,
+// laugh, hooray, confused, heart, rocket or eyes)
+type Reactions map[string]int
+
+// ReactionContents lists the reactions GitHub supports, in the order it shows them
+var ReactionContents = []string{"+1", "-1", "laugh", "hooray", "confused", "heart", "rocket", "eyes"}
+
+var reactionEmoji = map[string]string{
+	"+1":       "👍",
+	"-1":       "👎",
+	"laugh":    "😄",
+	"hooray":   "🎉",
+	"confused": "😕",
+	"heart":    "❤️",
+	"rocket":   "🚀",
+	"eyes":     "👀",
+}
+
+// graphQLReactions maps the GraphQL ReactionContent values to the REST names
+var graphQLReactions = map[string]string{
+	"THUMBS_UP":   "+1",
+	"THUMBS_DOWN": "-1",
+	"LAUGH":       "laugh",
+	"HOORAY":      "hooray",
+	"CONFUSED":    "confused",
+	"HEART":       "heart",
+	"ROCKET":      "rocket",
+	"EYES":        "eyes",
+}
+
+// reactionAliases are the other names accepted for reactions
+var reactionAliases = map[string]string{
+	"thumbsup":    "+1",
+	"thumbs_up":   "+1",
+	"like":        "+1",
+	"thumbsdown":  "-1",
+	"thumbs_down": "-1",
+	"tada":        "hooray",
+	"smile":       "laugh",
+	"love":        "heart",
+}
+
+// ReactionEmoji returns the emoji of a reaction
+func ReactionEmoji(content string) string {
+	return reactionEmoji[content]
+}
+
+// ParseReaction returns the REST name of a reaction given by name, alias or emoji
+func ParseReaction(s string) (string, error) {
+	name := strings.ToLower(strings.Trim(strings.TrimSpace(s), ":"))
+	if _, ok := reactionEmoji[name]; ok {
+		return name, nil
+	}
+	if content, ok := reactionAliases[name]; ok {
+		return content, nil
+	}
+	for content, emoji := range reactionEmoji {
+		if strings.TrimSuffix(strings.TrimSpace(s), "\ufe0f") == strings.TrimSuffix(emoji, "\ufe0f") {
+			return content, nil
+		}
+	}
+	return "", fmt.Errorf("unknown reaction %q (expected one of %s)", s, strings.Join(ReactionContents, ", "))
+}
+
+// String returns the reactions as "👍 2  👀 1", or "" when there are none
+func (r Reactions) String() string {
+	var parts []string
+	for _, content := range ReactionContents {
+		if count := r[content]; count > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", reactionEmoji[content], count))
+		}
+	}
+	return strings.Join(parts, "  ")
+}
+
+// rawReactions is the reaction summary of a REST comment
+type rawReactions struct {
+	PlusOne  int `json:"+1"`
+	MinusOne int `json:"-1"`
+	Laugh    int `json:"laugh"`
+	Hooray   int `json:"hooray"`
+	Confused int `json:"confused"`
+	Heart    int `json:"heart"`
+	Rocket   int `json:"rocket"`
+	Eyes     int `json:"eyes"`
+}
+
+func (r rawReactions) reactions() Reactions {
+	reactions := Reactions{}
+	for content, count := range map[string]int{
+		"+1": r.PlusOne, "-1": r.MinusOne, "laugh": r.Laugh, "hooray": r.Hooray,
+		"confused": r.Confused, "heart": r.Heart, "rocket": r.Rocket, "eyes": r.Eyes,
+	} {
+		if count > 0 {
+			reactions[content] = count
+		}
+	}
+	return reactions
+}
+
+// graphQLReactionGroups is the reactionGroups field of a GraphQL comment
+type graphQLReactionGroups []struct {
+	Content  string `json:"content"`
+	Reactors struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"reactors"`
+}
+
+func (groups graphQLReactionGroups) reactions() Reactions {
+	reactions := Reactions{}
+	for _, group := range groups {
+		if content, ok := graphQLReactions[group.Content]; ok && group.Reactors.TotalCount > 0 {
+			reactions[content] = group.Reactors.TotalCount
+		}
+	}
+	return reactions
+}
+
+// AddReaction reacts to a review comment and reports whether the reaction is new. Adding a
+// reaction we already left is a no-op.
+func (c *Client) AddReaction(commentID int64, content string) (bool, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return false, err
+	}
+
+	c.debugLog("Adding reaction %s to comment %d", content, commentID)
+
+	// GitHub answers 201 for a new reaction and 200 for one we already left
+	query := fmt.Sprintf("repos/%s/pulls/comments/%d/reactions", repo, commentID)
+	resp, err := c.restRequest(http.MethodPost, query, nil, map[string]string{"content": content})
+	if err != nil {
+		return false, fmt.Errorf("failed to react to comment %d: %w", commentID, err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusCreated, nil
+}
+
+// RemoveReaction removes our reaction from a review comment
+func (c *Client) RemoveReaction(commentID int64, content string) error {
+	repo, err := c.getRepo()
+	if err != nil {
+		return err
+	}
+	login, err := c.CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	type rawReaction struct {
+		ID   int64 `json:"id"`
+		User struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/comments/%d/reactions?content=%s&per_page=100", repo, commentID, url.QueryEscape(content))
+	reactions, err := restGetAll[rawReaction](c, query)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the reactions of comment %d: %w", commentID, err)
+	}
+
+	for _, reaction := range reactions {
+		if !strings.EqualFold(reaction.User.Login, login) {
+			continue
+		}
+		c.debugLog("Removing reaction %s (%d) from comment %d", content, reaction.ID, commentID)
+		query := fmt.Sprintf("repos/%s/pulls/comments/%d/reactions/%d", repo, commentID, reaction.ID)
+		if err := c.restDo(http.MethodDelete, query, nil, nil); err != nil {
+			return fmt.Errorf("failed to remove the reaction from comment %d: %w", commentID, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("you have not reacted with %s to comment %d", content, commentID)
+}
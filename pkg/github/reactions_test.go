@@ -0,0 +1,130 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseReaction(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "+1", want: "+1"},
+		{input: "EYES", want: "eyes"},
+		{input: ":tada:", want: "hooray"},
+		{input: "thumbsup", want: "+1"},
+		{input: "👀", want: "eyes"},
+		{input: "❤", want: "heart"},
+		{input: "❤️", want: "heart"},
+		{input: "shrug", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseReaction(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseReaction() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseReaction() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReactionsString(t *testing.T) {
+	if got := (Reactions{"eyes": 1, "+1": 2, "heart": 0}).String(); got != "👍 2  👀 1" {
+		t.Errorf("String() = %q", got)
+	}
+	if got := (Reactions{}).String(); got != "" {
+		t.Errorf("String() of no reactions = %q", got)
+	}
+}
+
+func TestReviewCommentReactions(t *testing.T) {
+	fake := &fakeGitHub{fixtures: map[string]string{
+		"threads:": "review_threads_page2.json",
+		"GET /repos/owner/repo/pulls/42/comments?per_page=100": "review_comments_reactions.json",
+	}}
+	client := newTestClient(t, fake)
+
+	comments, err := client.FetchReviewComments(42)
+	if err != nil {
+		t.Fatalf("FetchReviewComments() error = %v", err)
+	}
+	got := make(map[int64]Reactions)
+	for _, comment := range comments {
+		got[comment.ID] = comment.Reactions
+	}
+	want := map[int64]Reactions{
+		2001: {"heart": 1},
+		// The thread's reactions win over the REST ones, which may come from a stale cache
+		3001: {"+1": 2, "eyes": 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("reactions = %v, want %v", got, want)
+	}
+}
+
+func TestAddAndRemoveReaction(t *testing.T) {
+	tests := []struct {
+		content string
+		query   string
+		status  int
+	}{
+		{content: "eyes", query: "eyes", status: http.StatusCreated},
+		// A raw + would reach the server as a space
+		{content: "+1", query: "%2B1", status: http.StatusCreated},
+		// A reaction we already left
+		{content: "-1", query: "-1", status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.content, func(t *testing.T) {
+			fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+				"POST /repos/owner/repo/pulls/comments/1001/reactions": func(w http.ResponseWriter, r *http.Request) {
+					var body map[string]string
+					if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["content"] != tt.content {
+						t.Errorf("request = %v, %v", body, err)
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"id": 9}`))
+				},
+				"GET /user": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`{"login": "me"}`))
+				},
+				"GET /repos/owner/repo/pulls/comments/1001/reactions?content=" + tt.query + "&per_page=100": func(w http.ResponseWriter, r *http.Request) {
+					if got := r.URL.Query().Get("content"); got != tt.content {
+						t.Errorf("content = %q, want %q", got, tt.content)
+					}
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(`[{"id": 8, "user": {"login": "alice"}}, {"id": 9, "user": {"login": "me"}}]`))
+				},
+				"DELETE /repos/owner/repo/pulls/comments/1001/reactions/9": func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+				},
+			}}
+			client := newTestClient(t, fake)
+
+			created, err := client.AddReaction(1001, tt.content)
+			if err != nil {
+				t.Fatalf("AddReaction() error = %v", err)
+			}
+			if want := tt.status == http.StatusCreated; created != want {
+				t.Errorf("AddReaction() created = %v, want %v", created, want)
+			}
+			if err := client.RemoveReaction(1001, tt.content); err != nil {
+				t.Fatalf("RemoveReaction() error = %v", err)
+			}
+			if got := fake.requests[len(fake.requests)-1]; got != "DELETE /repos/owner/repo/pulls/comments/1001/reactions/9" {
+				t.Errorf("last request = %q, want the deletion of our reaction", got)
+			}
+		})
+	}
+}
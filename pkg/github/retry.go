@@ -0,0 +1,114 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// maxRetries is how many times a request is retried
+	maxRetries = 3
+
+	// maxRetryWait is the longest wait before a retry; when a rate limit resets later than
+	// that, the error is returned instead of hanging
+	maxRetryWait = time.Minute
+)
+
+// retryTransport retries requests rejected by the rate limits once they reset, and
+// idempotent requests failing with a transient server or network error with a backoff
+type retryTransport struct {
+	next  http.RoundTripper
+	log   func(format string, args ...any)
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func newRetryTransport(next http.RoundTripper, log func(format string, args ...any)) *retryTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &retryTransport{next: next, log: log, now: time.Now, sleep: sleepContext}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	hasBody := req.Body != nil && req.Body != http.NoBody
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+		if attempt > 0 && hasBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			attemptReq = req.Clone(req.Context())
+			attemptReq.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(attemptReq)
+		if attempt == maxRetries || (hasBody && req.GetBody == nil) {
+			return resp, err
+		}
+
+		wait, retry := t.retryDelay(req, resp, err, attempt)
+		if !retry || wait > maxRetryWait {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		t.log("Retrying %s %s in %s (attempt %d of %d)", req.Method, req.URL, wait, attempt+1, maxRetries)
+		if err := t.sleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay tells whether a response or error is worth retrying, and after how long
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	backoff := time.Second << attempt
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return 0, false
+		}
+		return backoff, idempotent
+	}
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// Secondary rate limits say how long to wait, primary ones when the quota resets
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				return 0, false
+			}
+			wait := time.Unix(reset, 0).Sub(t.now()) + time.Second
+			return max(wait, time.Second), true
+		}
+		return backoff, resp.StatusCode == http.StatusTooManyRequests
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return backoff, idempotent
+	default:
+		return 0, false
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,245 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PullRequest is the part of a pull request needed to review it from a local checkout
+type PullRequest struct {
+	Number  int
+	Title   string
+	HTMLURL string
+	HeadRef string
+	HeadSHA string
+	BaseSHA string
+}
+
+// PullRequestFile is a file changed by a pull request. Patch is empty for binary files and
+// diffs too large for the API.
+type PullRequestFile struct {
+	Path   string
+	Status string // added, removed, modified, renamed, ...
+	Patch  string
+}
+
+// DraftReviewComment is an inline comment of a review being created. StartLine is only set
+// for comments spanning several lines.
+type DraftReviewComment struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line"`
+	Side      string `json:"side,omitempty"`
+	StartLine int    `json:"start_line,omitempty"`
+	StartSide string `json:"start_side,omitempty"`
+	Body      string `json:"body"`
+}
+
+// rawReview is a review as returned by the REST API
+type rawReview struct {
+	ID      int64  `json:"id"`
+	NodeID  string `json:"node_id"`
+	State   string `json:"state"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	User    struct {
+		Login string `json:"login"`
+	} `json:"user"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+func (r rawReview) review() *Review {
+	return &Review{
+		ID:          r.ID,
+		NodeID:      r.NodeID,
+		Author:      r.User.Login,
+		State:       r.State,
+		Body:        r.Body,
+		HTMLURL:     r.HTMLURL,
+		SubmittedAt: r.SubmittedAt,
+	}
+}
+
+// GetPullRequest returns the head and base of a pull request
+func (c *Client) GetPullRequest(prNumber int) (*PullRequest, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	var raw struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		HTMLURL string `json:"html_url"`
+		Head    struct {
+			Ref string `json:"ref"`
+			SHA string `json:"sha"`
+		} `json:"head"`
+		Base struct {
+			SHA string `json:"sha"`
+		} `json:"base"`
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/%d", repo, prNumber)
+	if err := c.restDo(http.MethodGet, query, nil, &raw); err != nil {
+		return nil, fmt.Errorf("failed to fetch PR #%d: %w", prNumber, err)
+	}
+
+	return &PullRequest{
+		Number:  raw.Number,
+		Title:   raw.Title,
+		HTMLURL: raw.HTMLURL,
+		HeadRef: raw.Head.Ref,
+		HeadSHA: raw.Head.SHA,
+		BaseSHA: raw.Base.SHA,
+	}, nil
+}
+
+// FetchPullRequestFiles returns the files changed by a pull request with their patches
+func (c *Client) FetchPullRequestFiles(prNumber int) ([]*PullRequestFile, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	type rawFile struct {
+		Filename string `json:"filename"`
+		Status   string `json:"status"`
+		Patch    string `json:"patch"`
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/%d/files?per_page=100", repo, prNumber)
+	rawFiles, err := restGetAll[rawFile](c, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the files of PR #%d: %w", prNumber, err)
+	}
+
+	files := make([]*PullRequestFile, 0, len(rawFiles))
+	for _, raw := range rawFiles {
+		files = append(files, &PullRequestFile{Path: raw.Filename, Status: raw.Status, Patch: raw.Patch})
+	}
+	return files, nil
+}
+
+// CreateReview creates a review on commitID with inline comments. With an empty event the
+// review stays pending until it is submitted; otherwise event is APPROVE, REQUEST_CHANGES or
+// COMMENT.
+func (c *Client) CreateReview(prNumber int, commitID, body, event string, comments []DraftReviewComment) (*Review, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	request := struct {
+		CommitID string               `json:"commit_id,omitempty"`
+		Body     string               `json:"body,omitempty"`
+		Event    string               `json:"event,omitempty"`
+		Comments []DraftReviewComment `json:"comments,omitempty"`
+	}{commitID, body, event, comments}
+
+	c.debugLog("Creating review on PR #%d with %d comment(s)", prNumber, len(comments))
+
+	var raw rawReview
+	query := fmt.Sprintf("repos/%s/pulls/%d/reviews", repo, prNumber)
+	if err := c.restDo(http.MethodPost, query, request, &raw); err != nil {
+		return nil, fmt.Errorf("failed to create review: %w", err)
+	}
+
+	return raw.review(), nil
+}
+
+// PendingReview returns our pending review of a pull request, or nil when there is none.
+// Pending reviews are only visible to their author, so any listed is ours.
+func (c *Client) PendingReview(prNumber int) (*Review, error) {
+	reviews, err := c.FetchReviews(prNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, review := range reviews {
+		if review.State == "PENDING" {
+			return review, nil
+		}
+	}
+	return nil, nil
+}
+
+// AddPendingReviewComment adds an inline comment to a pending review, given its node ID
+func (c *Client) AddPendingReviewComment(reviewNodeID string, comment DraftReviewComment) error {
+	input := map[string]any{
+		"pullRequestReviewId": reviewNodeID,
+		"path":                comment.Path,
+		"body":                comment.Body,
+		"line":                comment.Line,
+	}
+	if comment.Side != "" {
+		input["side"] = comment.Side
+	}
+	if comment.StartLine > 0 {
+		input["startLine"] = comment.StartLine
+		input["startSide"] = comment.StartSide
+	}
+
+	mutation := `mutation($input: AddPullRequestReviewThreadInput!) {
+		addPullRequestReviewThread(input: $input) {
+			thread {
+				id
+			}
+		}
+	}`
+
+	var result struct {
+		AddPullRequestReviewThread struct {
+			Thread struct {
+				ID string `json:"id"`
+			} `json:"thread"`
+		} `json:"addPullRequestReviewThread"`
+	}
+
+	c.debugLog("Adding a comment on %s:%d to pending review %s", comment.Path, comment.Line, reviewNodeID)
+	if err := c.graphQL(mutation, map[string]any{"input": input}, &result); err != nil {
+		return fmt.Errorf("failed to add the comment to the pending review: %w", err)
+	}
+	if result.AddPullRequestReviewThread.Thread.ID == "" {
+		return fmt.Errorf("failed to add the comment to the pending review: GitHub did not create a thread on %s:%d", comment.Path, comment.Line)
+	}
+	return nil
+}
+
+// SubmitReview submits a pending review with event APPROVE, REQUEST_CHANGES or COMMENT
+func (c *Client) SubmitReview(prNumber int, reviewID int64, event, body string) (*Review, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	request := struct {
+		Event string `json:"event"`
+		Body  string `json:"body,omitempty"`
+	}{event, body}
+
+	c.debugLog("Submitting review %d on PR #%d as %s", reviewID, prNumber, event)
+
+	var raw rawReview
+	query := fmt.Sprintf("repos/%s/pulls/%d/reviews/%d/events", repo, prNumber, reviewID)
+	if err := c.restDo(http.MethodPost, query, request, &raw); err != nil {
+		return nil, fmt.Errorf("failed to submit review: %w", err)
+	}
+	return raw.review(), nil
+}
+
+// FetchReviewCommentsOfReview returns the inline comments of one review, including a pending
+// one of ours that is not visible in the PR comments yet
+func (c *Client) FetchReviewCommentsOfReview(prNumber int, reviewID int64) ([]*ReviewComment, error) {
+	repo, err := c.getRepo()
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("repos/%s/pulls/%d/reviews/%d/comments?per_page=100", repo, prNumber, reviewID)
+	rawComments, err := restGetAll[rawReviewComment](c, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the comments of review %d: %w", reviewID, err)
+	}
+
+	return c.buildReviewComments(rawComments, nil), nil
+}
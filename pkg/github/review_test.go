@@ -0,0 +1,146 @@
+package github
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCreateReview(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"POST /repos/owner/repo/pulls/42/reviews": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("invalid request: %v", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			want := map[string]any{
+				"commit_id": "abc123",
+				"event":     "REQUEST_CHANGES",
+				"body":      "Please fix",
+				"comments": []any{
+					map[string]any{"path": "main.go", "line": float64(12), "side": "RIGHT", "start_line": float64(10), "start_side": "RIGHT", "body": "Too long"},
+					map[string]any{"path": "util.go", "line": float64(3), "side": "LEFT", "body": "Why remove this?"},
+				},
+			}
+			if !reflect.DeepEqual(body, want) {
+				t.Errorf("request = %v, want %v", body, want)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": 77, "node_id": "PRR_77", "state": "CHANGES_REQUESTED", "user": {"login": "me"}}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	review, err := client.CreateReview(42, "abc123", "Please fix", "REQUEST_CHANGES", []DraftReviewComment{
+		{Path: "main.go", Line: 12, Side: "RIGHT", StartLine: 10, StartSide: "RIGHT", Body: "Too long"},
+		{Path: "util.go", Line: 3, Side: "LEFT", Body: "Why remove this?"},
+	})
+	if err != nil {
+		t.Fatalf("CreateReview() error = %v", err)
+	}
+	if review.ID != 77 || review.NodeID != "PRR_77" || review.State != "CHANGES_REQUESTED" || review.Author != "me" {
+		t.Errorf("CreateReview() = %+v", review)
+	}
+}
+
+func TestPendingReview(t *testing.T) {
+	tests := []struct {
+		name    string
+		reviews string
+		wantID  int64
+	}{
+		{
+			name:    "pending review",
+			reviews: `[{"id": 1, "state": "APPROVED"}, {"id": 2, "node_id": "PRR_2", "state": "PENDING"}]`,
+			wantID:  2,
+		},
+		{
+			name:    "no pending review",
+			reviews: `[{"id": 1, "state": "COMMENTED"}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+				"GET /repos/owner/repo/pulls/42/reviews": func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.Write([]byte(tt.reviews))
+				},
+			}}
+			client := newTestClient(t, fake)
+
+			review, err := client.PendingReview(42)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantID == 0 {
+				if review != nil {
+					t.Errorf("PendingReview() = %+v, want nil", review)
+				}
+				return
+			}
+			if review == nil || review.ID != tt.wantID || review.NodeID != "PRR_2" {
+				t.Errorf("PendingReview() = %+v", review)
+			}
+		})
+	}
+}
+
+func TestAddPendingReviewComment(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"addThread": func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data": {"addPullRequestReviewThread": {"thread": {"id": "PRRT_1"}}}}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	err := client.AddPendingReviewComment("PRR_2", DraftReviewComment{Path: "main.go", Line: 12, Side: "RIGHT", Body: "Nit"})
+	if err != nil {
+		t.Fatalf("AddPendingReviewComment() error = %v", err)
+	}
+	if !reflect.DeepEqual(fake.requests, []string{"addThread"}) {
+		t.Errorf("requests = %v", fake.requests)
+	}
+}
+
+func TestSubmitReview(t *testing.T) {
+	fake := &fakeGitHub{handlers: map[string]http.HandlerFunc{
+		"POST /repos/owner/repo/pulls/42/reviews/2/events": func(w http.ResponseWriter, r *http.Request) {
+			var body map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body["event"] != "APPROVE" || body["body"] != "LGTM" {
+				t.Errorf("request = %v, %v", body, err)
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id": 2, "state": "APPROVED", "html_url": "https://github.com/owner/repo/pull/42#pullrequestreview-2"}`))
+		},
+	}}
+	client := newTestClient(t, fake)
+
+	review, err := client.SubmitReview(42, 2, "APPROVE", "LGTM")
+	if err != nil {
+		t.Fatalf("SubmitReview() error = %v", err)
+	}
+	if review.State != "APPROVED" {
+		t.Errorf("SubmitReview() = %+v", review)
+	}
+}
+
+func TestFetchReviewCommentsOfReview(t *testing.T) {
+	fake := &fakeGitHub{fixtures: map[string]string{
+		"GET /repos/owner/repo/pulls/42/reviews/2/comments?per_page=100": "review_comments_page2.json",
+	}}
+	client := newTestClient(t, fake)
+
+	comments, err := client.FetchReviewCommentsOfReview(42, 2)
+	if err != nil {
+		t.Fatalf("FetchReviewCommentsOfReview() error = %v", err)
+	}
+	if len(comments) != 1 || comments[0].ID != 2001 || comments[0].ThreadID != "" {
+		t.Errorf("FetchReviewCommentsOfReview() = %+v", comments)
+	}
+}
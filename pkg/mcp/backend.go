@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"sync"
+
+	"github.com/chmouel/gh-prreview/pkg/applier"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// ClientBackend implements Backend with the GitHub client and the suggestion applier
+type ClientBackend struct {
+	client  *github.Client
+	applier *applier.Applier
+
+	prOnce sync.Once
+	pr     int
+	prErr  error
+}
+
+// NewClientBackend returns a backend acting on GitHub through client and on the working tree
+// through applier
+func NewClientBackend(client *github.Client, applier *applier.Applier) *ClientBackend {
+	return &ClientBackend{client: client, applier: applier}
+}
+
+// Repository returns the OWNER/REPO the server works on
+func (b *ClientBackend) Repository() (string, error) {
+	return b.client.GetRepo()
+}
+
+// CurrentPR returns the pull request of the current branch, looked up once
+func (b *ClientBackend) CurrentPR() (int, error) {
+	b.prOnce.Do(func() {
+		b.pr, b.prErr = b.client.GetCurrentBranchPR()
+	})
+	return b.pr, b.prErr
+}
+
+// ReviewComments returns the review threads of a pull request
+func (b *ClientBackend) ReviewComments(prNumber int) ([]*github.ReviewComment, error) {
+	return b.client.FetchReviewComments(prNumber)
+}
+
+// ApplySuggestion applies a suggestion to the working tree
+func (b *ClientBackend) ApplySuggestion(comment *github.ReviewComment) error {
+	return b.applier.ApplySuggestion(comment)
+}
+
+// Reply posts a reply in the thread of a review comment
+func (b *ClientBackend) Reply(prNumber int, commentID int64, body string) (*github.ThreadComment, error) {
+	return b.client.ReplyToComment(prNumber, commentID, body)
+}
+
+// ResolveThread marks a review thread as resolved
+func (b *ClientBackend) ResolveThread(threadID string) error {
+	return b.client.ResolveThread(threadID)
+}
@@ -0,0 +1,260 @@
+// Package mcp implements a Model Context Protocol server over stdio exposing the review
+// comments of a pull request, and optionally actions on them, as tools for coding agents.
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// supportedProtocolVersions are the MCP revisions this server speaks, newest first
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// Backend is what the tools act on
+type Backend interface {
+	Repository() (string, error)
+	CurrentPR() (int, error)
+	ReviewComments(prNumber int) ([]*github.ReviewComment, error)
+	ApplySuggestion(comment *github.ReviewComment) error
+	Reply(prNumber int, commentID int64, body string) (*github.ThreadComment, error)
+	ResolveThread(threadID string) error
+}
+
+// Server answers MCP requests read line by line from a stream
+type Server struct {
+	backend Backend
+	allowed map[string]bool
+
+	mu  sync.Mutex
+	out *json.Encoder
+}
+
+// NewServer returns a server over backend. Read-only tools are always available; write tools
+// are only listed and callable when named in allowedWriteTools.
+func NewServer(backend Backend, allowedWriteTools []string) (*Server, error) {
+	allowed := make(map[string]bool, len(allowedWriteTools))
+	for _, name := range allowedWriteTools {
+		tool, ok := toolsByName[name]
+		if !ok || !tool.write {
+			return nil, fmt.Errorf("unknown write tool %q (expected one of %s)", name, strings.Join(WriteTools(), ", "))
+		}
+		allowed[name] = true
+	}
+	return &Server{backend: backend, allowed: allowed}, nil
+}
+
+// WriteTools returns the names of the tools that modify the pull request or the working tree
+func WriteTools() []string {
+	var names []string
+	for _, tool := range tools {
+		if tool.write {
+			names = append(names, tool.name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  any             `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// Serve handles newline-delimited JSON-RPC messages from r until it is exhausted or ctx is
+// cancelled, writing the responses to w
+func (s *Server) Serve(ctx context.Context, r io.Reader, w io.Writer) error {
+	s.out = json.NewEncoder(w)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := s.handleMessage([]byte(line)); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (s *Server) handleMessage(message []byte) error {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return s.write(response{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: err.Error()}})
+	}
+
+	// Notifications get no response
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return s.write(response{JSONRPC: "2.0", ID: req.ID, Error: &rpcError{Code: codeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"}})
+	}
+
+	result, rpcErr := s.dispatch(req)
+	resp := response{JSONRPC: "2.0", ID: req.ID, Result: result, Error: rpcErr}
+	if rpcErr == nil && result == nil {
+		resp.Result = struct{}{}
+	}
+	return s.write(resp)
+}
+
+func (s *Server) write(resp response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.out.Encode(resp); err != nil {
+		return fmt.Errorf("failed to write MCP response: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) dispatch(req request) (any, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return nil, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(req.Params)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)}
+	}
+}
+
+func (s *Server) initialize(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+	}
+
+	version := supportedProtocolVersions[0]
+	for _, supported := range supportedProtocolVersions {
+		if p.ProtocolVersion == supported {
+			version = supported
+			break
+		}
+	}
+
+	return map[string]any{
+		"protocolVersion": version,
+		"capabilities": map[string]any{
+			"tools": map[string]any{},
+		},
+		"serverInfo": map[string]any{
+			"name":    "gh-prreview",
+			"version": serverVersion(),
+		},
+		"instructions": "Tools to read the review comments of a GitHub pull request and act on them. " +
+			"The pull request defaults to the one of the current branch.",
+	}, nil
+}
+
+// serverVersion is the module version the binary was built from
+func serverVersion() string {
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+func (s *Server) listTools() any {
+	list := make([]map[string]any, 0, len(tools))
+	for _, tool := range tools {
+		if tool.write && !s.allowed[tool.name] {
+			continue
+		}
+		list = append(list, map[string]any{
+			"name":        tool.name,
+			"description": tool.description,
+			"inputSchema": tool.schema,
+		})
+	}
+	return map[string]any{"tools": list}
+}
+
+func (s *Server) callTool(params json.RawMessage) (any, *rpcError) {
+	var p struct {
+		Name      string          `json:"name"`
+		Arguments json.RawMessage `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+
+	tool, ok := toolsByName[p.Name]
+	if !ok {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool %q", p.Name)}
+	}
+	if tool.write && !s.allowed[tool.name] {
+		return toolError(fmt.Errorf("tool %q is not allowed; start the server with --allow %s", tool.name, tool.name)), nil
+	}
+
+	var args toolArgs
+	if len(p.Arguments) > 0 && string(p.Arguments) != "null" {
+		if err := json.Unmarshal(p.Arguments, &args); err != nil {
+			return toolError(fmt.Errorf("invalid arguments: %w", err)), nil
+		}
+	}
+
+	text, err := tool.run(s.backend, args)
+	if err != nil {
+		return toolError(err), nil
+	}
+	return toolResult(text, false), nil
+}
+
+// toolError reports a failed tool call to the agent, as opposed to a protocol error
+func toolError(err error) any {
+	return toolResult(err.Error(), true)
+}
+
+func toolResult(text string, isError bool) any {
+	return map[string]any{
+		"content": []map[string]any{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+type fakeBackend struct {
+	comments []*github.ReviewComment
+	applied  []int64
+	replies  []string
+	resolved []string
+}
+
+func (f *fakeBackend) Repository() (string, error) { return "o/r", nil }
+func (f *fakeBackend) CurrentPR() (int, error)     { return 7, nil }
+
+func (f *fakeBackend) ReviewComments(prNumber int) ([]*github.ReviewComment, error) {
+	if prNumber != 7 {
+		return nil, fmt.Errorf("no PR #%d", prNumber)
+	}
+	return f.comments, nil
+}
+
+func (f *fakeBackend) ApplySuggestion(comment *github.ReviewComment) error {
+	f.applied = append(f.applied, comment.ID)
+	return nil
+}
+
+func (f *fakeBackend) Reply(prNumber int, commentID int64, body string) (*github.ThreadComment, error) {
+	f.replies = append(f.replies, fmt.Sprintf("%d:%s", commentID, body))
+	return &github.ThreadComment{ID: 99, Body: body, HTMLURL: "https://github.com/o/r/pull/7#discussion_r99"}, nil
+}
+
+func (f *fakeBackend) ResolveThread(threadID string) error {
+	f.resolved = append(f.resolved, threadID)
+	return nil
+}
+
+func newFakeBackend() *fakeBackend {
+	return &fakeBackend{comments: []*github.ReviewComment{
+		{
+			ID: 1, ThreadID: "PRRT_1", Path: "main.go", Line: 3, EndLine: 3, SubjectType: "line",
+			Author: "alice", Body: "fix\n```suggestion\nfixed\n```", HasSuggestion: true, SuggestedCode: "fixed",
+		},
+		{ID: 2, ThreadID: "PRRT_2", Path: "old.go", Line: 1, SubjectType: "line", Author: "bob", Body: "done", Resolved: true},
+	}}
+}
+
+type rpcResponse struct {
+	ID     int             `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+// roundTrip sends the messages to a server and returns its responses by request ID
+func roundTrip(t *testing.T, server *Server, messages ...string) map[int]rpcResponse {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader(strings.Join(messages, "\n")), &out); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+
+	responses := make(map[int]rpcResponse)
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var resp rpcResponse
+		if err := decoder.Decode(&resp); err != nil {
+			t.Fatalf("invalid response: %v", err)
+		}
+		responses[resp.ID] = resp
+	}
+	return responses
+}
+
+type callResult struct {
+	Content []struct {
+		Text string `json:"text"`
+	} `json:"content"`
+	IsError bool `json:"isError"`
+}
+
+func decodeCall(t *testing.T, resp rpcResponse) callResult {
+	t.Helper()
+	if resp.Error != nil {
+		t.Fatalf("unexpected protocol error: %+v", resp.Error)
+	}
+	var result callResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatalf("invalid tools/call result: %v", err)
+	}
+	return result
+}
+
+func TestNewServer(t *testing.T) {
+	if _, err := NewServer(newFakeBackend(), []string{"reply", "resolve-thread"}); err != nil {
+		t.Errorf("NewServer() with write tools error = %v", err)
+	}
+	for _, name := range []string{"list-comments", "push"} {
+		if _, err := NewServer(newFakeBackend(), []string{name}); err == nil {
+			t.Errorf("NewServer() should reject %q in the allow-list", name)
+		}
+	}
+}
+
+func TestServerProtocol(t *testing.T) {
+	server, _ := NewServer(newFakeBackend(), nil)
+	responses := roundTrip(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+		`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/list"}`,
+	)
+
+	if len(responses) != 4 {
+		t.Fatalf("got %d responses, want 4 (notifications are not answered)", len(responses))
+	}
+
+	var initResult struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(responses[1].Result, &initResult); err != nil || initResult.ProtocolVersion != "2024-11-05" {
+		t.Errorf("initialize should agree on the client version, got %s", responses[1].Result)
+	}
+
+	if responses[2].Error != nil {
+		t.Errorf("ping failed: %+v", responses[2].Error)
+	}
+
+	if responses[3].Error == nil || responses[3].Error.Code != codeMethodNotFound {
+		t.Errorf("unknown method should fail with %d, got %+v", codeMethodNotFound, responses[3].Error)
+	}
+
+	var list struct {
+		Tools []struct {
+			Name string `json:"name"`
+		} `json:"tools"`
+	}
+	if err := json.Unmarshal(responses[4].Result, &list); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, tool := range list.Tools {
+		names = append(names, tool.Name)
+	}
+	if got := strings.Join(names, ","); got != "list-comments,get-thread" {
+		t.Errorf("tools/list = %s, want only the read-only tools", got)
+	}
+}
+
+func TestServerParseError(t *testing.T) {
+	server, _ := NewServer(newFakeBackend(), nil)
+
+	var out bytes.Buffer
+	if err := server.Serve(context.Background(), strings.NewReader("{not json"), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), fmt.Sprintf(`"code":%d`, codeParseError)) {
+		t.Errorf("expected a parse error, got %s", out.String())
+	}
+}
+
+func TestReadTools(t *testing.T) {
+	server, _ := NewServer(newFakeBackend(), nil)
+	responses := roundTrip(t, server,
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"list-comments","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"list-comments","arguments":{"include_resolved":true}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get-thread","arguments":{"thread_id":"PRRT_1"}}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get-thread","arguments":{"comment_id":42}}}`,
+	)
+
+	var doc struct {
+		PullRequest int `json:"pull_request"`
+		Comments    []struct {
+			ID int64 `json:"id"`
+		} `json:"comments"`
+	}
+	if err := json.Unmarshal([]byte(decodeCall(t, responses[1]).Content[0].Text), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.PullRequest != 7 || len(doc.Comments) != 1 {
+		t.Errorf("list-comments should list the unresolved thread of the current PR, got %+v", doc)
+	}
+
+	if err := json.Unmarshal([]byte(decodeCall(t, responses[2]).Content[0].Text), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Comments) != 2 {
+		t.Errorf("include_resolved should list both threads, got %d", len(doc.Comments))
+	}
+
+	thread := decodeCall(t, responses[3])
+	if thread.IsError || !strings.Contains(thread.Content[0].Text, "THREAD_ID: PRRT_1") {
+		t.Errorf("get-thread returned %+v", thread)
+	}
+
+	missing := decodeCall(t, responses[4])
+	if !missing.IsError || !strings.Contains(missing.Content[0].Text, "comment 42 not found") {
+		t.Errorf("get-thread on a missing comment should be a tool error, got %+v", missing)
+	}
+}
+
+func TestWriteTools(t *testing.T) {
+	calls := []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"apply-suggestion","arguments":{"comment_id":1}}}`,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"reply","arguments":{"comment_id":1,"body":"Fixed"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"resolve-thread","arguments":{"comment_id":1}}}`,
+	}
+
+	t.Run("not allowed", func(t *testing.T) {
+		backend := newFakeBackend()
+		server, _ := NewServer(backend, nil)
+		responses := roundTrip(t, server, calls...)
+		for id := 1; id <= 3; id++ {
+			if result := decodeCall(t, responses[id]); !result.IsError || !strings.Contains(result.Content[0].Text, "not allowed") {
+				t.Errorf("call %d should be refused, got %+v", id, result)
+			}
+		}
+		if len(backend.applied)+len(backend.replies)+len(backend.resolved) != 0 {
+			t.Error("refused calls must not reach the backend")
+		}
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		backend := newFakeBackend()
+		server, _ := NewServer(backend, WriteTools())
+		responses := roundTrip(t, server, calls...)
+		for id := 1; id <= 3; id++ {
+			if result := decodeCall(t, responses[id]); result.IsError {
+				t.Errorf("call %d failed: %+v", id, result)
+			}
+		}
+		if len(backend.applied) != 1 || backend.applied[0] != 1 {
+			t.Errorf("applied = %v, want [1]", backend.applied)
+		}
+		if len(backend.replies) != 1 || backend.replies[0] != "1:Fixed" {
+			t.Errorf("replies = %v, want [1:Fixed]", backend.replies)
+		}
+		if len(backend.resolved) != 1 || backend.resolved[0] != "PRRT_1" {
+			t.Errorf("resolved = %v, want [PRRT_1]", backend.resolved)
+		}
+	})
+
+	t.Run("suggestion required", func(t *testing.T) {
+		server, _ := NewServer(newFakeBackend(), []string{"apply-suggestion"})
+		responses := roundTrip(t, server,
+			`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"apply-suggestion","arguments":{"comment_id":2}}}`)
+		if result := decodeCall(t, responses[1]); !result.IsError {
+			t.Errorf("applying a comment without suggestion should fail, got %+v", result)
+		}
+	})
+}
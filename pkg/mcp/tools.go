@@ -0,0 +1,270 @@
+package mcp
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/format"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// toolArgs holds the arguments of every tool; each tool reads the ones it declares
+type toolArgs struct {
+	PR              int    `json:"pr"`
+	CommentID       int64  `json:"comment_id"`
+	ThreadID        string `json:"thread_id"`
+	Body            string `json:"body"`
+	IncludeResolved bool   `json:"include_resolved"`
+	ContextLines    *int   `json:"context_lines"`
+}
+
+type tool struct {
+	name        string
+	description string
+	schema      map[string]any
+	// write tools change the pull request or the working tree and must be allowed explicitly
+	write bool
+	run   func(Backend, toolArgs) (string, error)
+}
+
+var prProperty = map[string]any{
+	"type":        "integer",
+	"description": "Pull request number; defaults to the pull request of the current branch",
+}
+
+var commentIDProperty = map[string]any{
+	"type":        "integer",
+	"description": "ID of the first comment of the review thread (COMMENT_ID / id)",
+}
+
+var threadIDProperty = map[string]any{
+	"type":        "string",
+	"description": "GraphQL ID of the review thread (THREAD_ID / thread_id)",
+}
+
+func objectSchema(properties map[string]any, required ...string) map[string]any {
+	schema := map[string]any{
+		"type":       "object",
+		"properties": properties,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+var tools = []tool{
+	{
+		name: "list-comments",
+		description: "List the review threads of a pull request as JSON: location, scope, line range " +
+			"(and where the lines are in the local checkout), author, body, suggestion, replies and " +
+			"resolved/outdated state. Resolved threads are left out unless include_resolved is set.",
+		schema: objectSchema(map[string]any{
+			"pr": prProperty,
+			"include_resolved": map[string]any{
+				"type":        "boolean",
+				"description": "Also list resolved threads",
+			},
+		}),
+		run: runListComments,
+	},
+	{
+		name: "get-thread",
+		description: "Show one review thread with the current local code around it and its diff hunk. " +
+			"Identify it with thread_id or comment_id.",
+		schema: objectSchema(map[string]any{
+			"pr":         prProperty,
+			"thread_id":  threadIDProperty,
+			"comment_id": commentIDProperty,
+			"context_lines": map[string]any{
+				"type":        "integer",
+				"description": "Number of surrounding lines of local code to show (default 5)",
+			},
+		}),
+		run: runGetThread,
+	},
+	{
+		name:        "apply-suggestion",
+		description: "Apply the suggested change of a review comment to the local working tree.",
+		schema: objectSchema(map[string]any{
+			"pr":         prProperty,
+			"comment_id": commentIDProperty,
+		}, "comment_id"),
+		write: true,
+		run:   runApplySuggestion,
+	},
+	{
+		name:        "reply",
+		description: "Post a reply in a review thread.",
+		schema: objectSchema(map[string]any{
+			"pr":         prProperty,
+			"comment_id": commentIDProperty,
+			"body": map[string]any{
+				"type":        "string",
+				"description": "Markdown body of the reply",
+			},
+		}, "comment_id", "body"),
+		write: true,
+		run:   runReply,
+	},
+	{
+		name:        "resolve-thread",
+		description: "Mark a review thread as resolved. Identify it with thread_id or comment_id.",
+		schema: objectSchema(map[string]any{
+			"pr":         prProperty,
+			"thread_id":  threadIDProperty,
+			"comment_id": commentIDProperty,
+		}),
+		write: true,
+		run:   runResolveThread,
+	},
+}
+
+var toolsByName = make(map[string]*tool, len(tools))
+
+func init() {
+	for i := range tools {
+		toolsByName[tools[i].name] = &tools[i]
+	}
+}
+
+// prNumber returns the requested pull request, or the one of the current branch
+func prNumber(backend Backend, args toolArgs) (int, error) {
+	if args.PR > 0 {
+		return args.PR, nil
+	}
+	return backend.CurrentPR()
+}
+
+// findThread returns the review thread matching the thread or comment ID of args
+func findThread(backend Backend, args toolArgs) (int, *github.ReviewComment, error) {
+	if args.ThreadID == "" && args.CommentID == 0 {
+		return 0, nil, fmt.Errorf("thread_id or comment_id is required")
+	}
+
+	pr, err := prNumber(backend, args)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	comments, err := backend.ReviewComments(pr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for _, comment := range comments {
+		if (args.ThreadID != "" && comment.ThreadID == args.ThreadID) || (args.CommentID != 0 && comment.ID == args.CommentID) {
+			return pr, comment, nil
+		}
+	}
+
+	if args.ThreadID != "" {
+		return 0, nil, fmt.Errorf("thread %s not found in PR #%d", args.ThreadID, pr)
+	}
+	return 0, nil, fmt.Errorf("comment %d not found in PR #%d", args.CommentID, pr)
+}
+
+func runListComments(backend Backend, args toolArgs) (string, error) {
+	pr, err := prNumber(backend, args)
+	if err != nil {
+		return "", err
+	}
+
+	repo, err := backend.Repository()
+	if err != nil {
+		return "", err
+	}
+
+	comments, err := backend.ReviewComments(pr)
+	if err != nil {
+		return "", err
+	}
+
+	filtered := make([]*github.ReviewComment, 0, len(comments))
+	for _, comment := range comments {
+		if args.IncludeResolved || !comment.IsResolved() {
+			filtered = append(filtered, comment)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := format.WriteJSON(&buf, format.NewDocument(repo, pr, filtered, nil, nil)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func runGetThread(backend Backend, args toolArgs) (string, error) {
+	_, comment, err := findThread(backend, args)
+	if err != nil {
+		return "", err
+	}
+
+	contextLines := 5
+	if args.ContextLines != nil && *args.ContextLines >= 0 {
+		contextLines = *args.ContextLines
+	}
+
+	output := format.RenderLLM([]*github.ReviewComment{comment}, format.LLMOptions{
+		DiffHunk:     true,
+		CurrentCode:  true,
+		ContextLines: contextLines,
+	})
+	block, _ := output.Block(comment)
+	return block, nil
+}
+
+func runApplySuggestion(backend Backend, args toolArgs) (string, error) {
+	_, comment, err := findThread(backend, toolArgs{PR: args.PR, CommentID: args.CommentID})
+	if err != nil {
+		return "", err
+	}
+	if !comment.HasSuggestion {
+		return "", fmt.Errorf("comment %d has no suggestion", comment.ID)
+	}
+
+	if err := backend.ApplySuggestion(comment); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Applied suggestion from comment %d to %s", comment.ID, comment.Location()), nil
+}
+
+func runReply(backend Backend, args toolArgs) (string, error) {
+	if args.CommentID == 0 {
+		return "", fmt.Errorf("comment_id is required")
+	}
+	if strings.TrimSpace(args.Body) == "" {
+		return "", fmt.Errorf("body is required")
+	}
+
+	pr, err := prNumber(backend, args)
+	if err != nil {
+		return "", err
+	}
+
+	reply, err := backend.Reply(pr, args.CommentID, args.Body)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Replied to comment %d: %s", args.CommentID, reply.HTMLURL), nil
+}
+
+func runResolveThread(backend Backend, args toolArgs) (string, error) {
+	threadID := args.ThreadID
+	if threadID == "" {
+		_, comment, err := findThread(backend, args)
+		if err != nil {
+			return "", err
+		}
+		if comment.ThreadID == "" {
+			return "", fmt.Errorf("comment %d has no review thread", comment.ID)
+		}
+		threadID = comment.ThreadID
+	}
+
+	if err := backend.ResolveThread(threadID); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Resolved thread %s", threadID), nil
+}
@@ -0,0 +1,245 @@
+// Package suggest turns local edits of a pull request's code into review suggestions, the
+// reverse of applying them.
+package suggest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chmouel/gh-prreview/pkg/diffhunk"
+	"github.com/chmouel/gh-prreview/pkg/diffposition"
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// FileDiff is the local diff of one file against the PR head
+type FileDiff struct {
+	Path    string
+	Deleted bool
+	Added   bool
+	Patch   string // the hunks, without the file header
+}
+
+// Suggestion is a local change expressed as a suggestion replacing lines of the PR head
+type Suggestion struct {
+	Path        string
+	StartLine   int      // first replaced line of the PR head
+	EndLine     int      // last replaced line of the PR head
+	Original    []string // the replaced lines
+	Replacement []string // the suggested lines, empty to delete the original ones
+	Position    *diffposition.CommentPosition
+}
+
+// Skipped is a local change that cannot be posted as a suggestion
+type Skipped struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Reason    string
+}
+
+// ParseGitDiff splits the output of git diff into one patch per file
+func ParseGitDiff(diff string) []FileDiff {
+	var files []FileDiff
+	var current *FileDiff
+	var patch strings.Builder
+
+	flush := func() {
+		if current != nil {
+			current.Patch = patch.String()
+			files = append(files, *current)
+		}
+		current = nil
+		patch.Reset()
+	}
+
+	inHeader := false
+	for _, line := range strings.Split(strings.TrimSuffix(diff, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			flush()
+			current = &FileDiff{}
+			inHeader = true
+		case current == nil:
+		case inHeader && strings.HasPrefix(line, "--- "):
+			if path, ok := diffPath(line[4:], "a/"); ok {
+				current.Path = path
+			} else {
+				current.Added = true
+			}
+		case inHeader && strings.HasPrefix(line, "+++ "):
+			if path, ok := diffPath(line[4:], "b/"); ok {
+				current.Path = path
+			} else {
+				current.Deleted = true
+			}
+		case strings.HasPrefix(line, "@@"):
+			inHeader = false
+			patch.WriteString(line + "\n")
+		case !inHeader:
+			patch.WriteString(line + "\n")
+		}
+	}
+	flush()
+
+	return files
+}
+
+// diffPath returns the path of a ---/+++ header line, false for /dev/null
+func diffPath(name, prefix string) (string, bool) {
+	if name == "/dev/null" {
+		return "", false
+	}
+	return strings.TrimPrefix(strings.TrimSuffix(name, "\t"), prefix), true
+}
+
+// Build maps the local diffs, made with zero lines of context (git diff -U0), to suggestions
+// on the lines the PR changes. prPatches holds the PR patch of each file and headLines reads
+// a file as of the PR head. Changes outside the lines GitHub lets comment on are skipped.
+func Build(files []FileDiff, prPatches map[string]string, headLines func(path string) ([]string, error)) ([]*Suggestion, []Skipped, error) {
+	var suggestions []*Suggestion
+	var skipped []Skipped
+
+	for _, file := range files {
+		if file.Added || file.Deleted {
+			reason := "file added locally"
+			if file.Deleted {
+				reason = "file deleted locally"
+			}
+			skipped = append(skipped, Skipped{Path: file.Path, Reason: reason})
+			continue
+		}
+		if strings.TrimSpace(file.Patch) == "" {
+			continue
+		}
+
+		prPatch, ok := prPatches[file.Path]
+		if !ok || prPatch == "" {
+			skipped = append(skipped, Skipped{Path: file.Path, Reason: "file not changed by the pull request"})
+			continue
+		}
+		ranges, err := diffposition.GetCommentingRanges(prPatch, false)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the PR diff of %s: %w", file.Path, err)
+		}
+
+		hunks, err := diffhunk.ParsePatch(file.Patch)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse the local diff of %s: %w", file.Path, err)
+		}
+		lines, err := headLines(file.Path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s at the PR head: %w", file.Path, err)
+		}
+
+		for _, hunk := range hunks {
+			suggestion, reason := fromHunk(file.Path, hunk, lines)
+			if suggestion != nil && !diffposition.IsRangeCommentable(ranges, suggestion.StartLine, suggestion.EndLine) {
+				suggestion, reason = nil, "lines not part of the pull request diff"
+			}
+			if suggestion == nil {
+				start, end := hunkRange(hunk)
+				skipped = append(skipped, Skipped{Path: file.Path, StartLine: start, EndLine: end, Reason: reason})
+				continue
+			}
+
+			pos, err := diffposition.CalculateCommentPosition(suggestion.EndLine, suggestion.EndLine, prPatch, diffposition.DiffSideRight)
+			if err != nil {
+				return nil, nil, err
+			}
+			pos.StartLine = suggestion.StartLine
+			pos.OriginalStartLine = suggestion.StartLine
+			suggestion.Position = pos
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions, skipped, nil
+}
+
+// fromHunk turns a zero-context hunk into a suggestion. A pure insertion has no line to
+// replace, so it is anchored on the line before it (or after it, at the top of the file).
+func fromHunk(path string, hunk *diffhunk.DiffHunk, lines []string) (*Suggestion, string) {
+	var added []string
+	for _, line := range hunk.Lines {
+		if line.Type == diffhunk.Add {
+			added = append(added, line.Text)
+		}
+	}
+
+	start, end := hunkRange(hunk)
+	if end > len(lines) || start < 1 {
+		return nil, "change outside of the file at the PR head"
+	}
+	original := append([]string(nil), lines[start-1:end]...)
+
+	replacement := added
+	if hunk.OldLines == 0 {
+		if hunk.OldStart == 0 {
+			replacement = append(added, original...)
+		} else {
+			replacement = append(append([]string(nil), original...), added...)
+		}
+	}
+
+	return &Suggestion{
+		Path:        path,
+		StartLine:   start,
+		EndLine:     end,
+		Original:    original,
+		Replacement: replacement,
+	}, ""
+}
+
+// hunkRange returns the lines of the PR head a hunk replaces, or the line an insertion is
+// anchored on
+func hunkRange(hunk *diffhunk.DiffHunk) (int, int) {
+	if hunk.OldLines == 0 {
+		anchor := max(hunk.OldStart, 1)
+		return anchor, anchor
+	}
+	return hunk.OldStart, hunk.OldStart + hunk.OldLines - 1
+}
+
+// Body returns the comment posting the suggestion, with prose before the suggestion block
+func (s *Suggestion) Body(prose string) string {
+	code := strings.Join(s.Replacement, "\n")
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	var b strings.Builder
+	if prose = strings.TrimSpace(prose); prose != "" {
+		b.WriteString(prose + "\n\n")
+	}
+	b.WriteString(fence + "suggestion\n")
+	if code != "" {
+		b.WriteString(code + "\n")
+	}
+	b.WriteString(fence)
+	return b.String()
+}
+
+// Location returns path:line or path:start-end
+func (s *Suggestion) Location() string {
+	if s.StartLine == s.EndLine {
+		return fmt.Sprintf("%s:%d", s.Path, s.EndLine)
+	}
+	return fmt.Sprintf("%s:%d-%d", s.Path, s.StartLine, s.EndLine)
+}
+
+// Comment returns the draft review comment posting the suggestion at its position
+func (s *Suggestion) Comment(prose string) github.DraftReviewComment {
+	pos := s.Position
+	comment := github.DraftReviewComment{
+		Path: s.Path,
+		Line: pos.EndLine,
+		Side: string(pos.DiffSide),
+		Body: s.Body(prose),
+	}
+	if pos.StartLine != pos.EndLine {
+		comment.StartLine = pos.StartLine
+		comment.StartSide = string(pos.DiffSide)
+	}
+	return comment
+}
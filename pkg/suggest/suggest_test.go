@@ -0,0 +1,193 @@
+package suggest
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// The PR changes lines 3-5 of main.go, a file of 10 lines
+const prPatch = `@@ -1,7 +1,7 @@
+ line1
+ line2
+-old3
++line3
+ line4
+ line5
+ line6
+ line7
+`
+
+var headFile = []string{"line1", "line2", "line3", "line4", "line5", "line6", "line7", "line8", "line9", "line10"}
+
+func TestParseGitDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -3 +3 @@
+-line3
++LINE3
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 4444444..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package main
+`
+
+	got := ParseGitDiff(diff)
+	want := []FileDiff{
+		{Path: "main.go", Patch: "@@ -3 +3 @@\n-line3\n+LINE3\n"},
+		{Path: "new.go", Added: true, Patch: "@@ -0,0 +1 @@\n+package main\n"},
+		{Path: "gone.go", Deleted: true, Patch: "@@ -1 +0,0 @@\n-package main\n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseGitDiff() = %+v, want %+v", got, want)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		name            string
+		patch           string
+		wantStart       int
+		wantEnd         int
+		wantReplacement []string
+		wantSkipped     string
+	}{
+		{
+			name:            "replaced line",
+			patch:           "@@ -3 +3 @@\n-line3\n+LINE3\n",
+			wantStart:       3,
+			wantEnd:         3,
+			wantReplacement: []string{"LINE3"},
+		},
+		{
+			name:            "replaced range",
+			patch:           "@@ -3,2 +3 @@\n-line3\n-line4\n+line3and4\n",
+			wantStart:       3,
+			wantEnd:         4,
+			wantReplacement: []string{"line3and4"},
+		},
+		{
+			name:            "insertion anchored on the previous line",
+			patch:           "@@ -4,0 +5 @@\n+inserted\n",
+			wantStart:       4,
+			wantEnd:         4,
+			wantReplacement: []string{"line4", "inserted"},
+		},
+		{
+			name:            "insertion at the top of the file",
+			patch:           "@@ -0,0 +1 @@\n+// header\n",
+			wantStart:       1,
+			wantEnd:         1,
+			wantReplacement: []string{"// header", "line1"},
+		},
+		{
+			name:      "deleted lines",
+			patch:     "@@ -5,2 +4,0 @@\n-line5\n-line6\n",
+			wantStart: 5,
+			wantEnd:   6,
+		},
+		{
+			name:        "outside the PR diff",
+			patch:       "@@ -9 +9 @@\n-line9\n+LINE9\n",
+			wantSkipped: "lines not part of the pull request diff",
+		},
+		{
+			name:        "spanning lines outside the PR diff",
+			patch:       "@@ -7,2 +7 @@\n-line7\n-line8\n+merged\n",
+			wantSkipped: "lines not part of the pull request diff",
+		},
+	}
+
+	headLines := func(path string) ([]string, error) { return headFile, nil }
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := []FileDiff{{Path: "main.go", Patch: tt.patch}}
+			suggestions, skipped, err := Build(files, map[string]string{"main.go": prPatch}, headLines)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantSkipped != "" {
+				if len(suggestions) != 0 || len(skipped) != 1 || skipped[0].Reason != tt.wantSkipped {
+					t.Fatalf("got suggestions %+v, skipped %+v", suggestions, skipped)
+				}
+				return
+			}
+			if len(suggestions) != 1 || len(skipped) != 0 {
+				t.Fatalf("got suggestions %+v, skipped %+v", suggestions, skipped)
+			}
+			s := suggestions[0]
+			if s.StartLine != tt.wantStart || s.EndLine != tt.wantEnd {
+				t.Errorf("range = %d-%d, want %d-%d", s.StartLine, s.EndLine, tt.wantStart, tt.wantEnd)
+			}
+			if !reflect.DeepEqual(s.Replacement, tt.wantReplacement) {
+				t.Errorf("replacement = %q, want %q", s.Replacement, tt.wantReplacement)
+			}
+			if want := headFile[tt.wantStart-1 : tt.wantEnd]; !reflect.DeepEqual(s.Original, want) {
+				t.Errorf("original = %q, want %q", s.Original, want)
+			}
+		})
+	}
+}
+
+func TestBuildSkipsFiles(t *testing.T) {
+	files := []FileDiff{
+		{Path: "other.go", Patch: "@@ -1 +1 @@\n-a\n+b\n"},
+		{Path: "new.go", Added: true, Patch: "@@ -0,0 +1 @@\n+a\n"},
+	}
+	headLines := func(path string) ([]string, error) { return headFile, nil }
+
+	suggestions, skipped, err := Build(files, map[string]string{"main.go": prPatch}, headLines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suggestions) != 0 || len(skipped) != 2 {
+		t.Fatalf("got suggestions %+v, skipped %+v", suggestions, skipped)
+	}
+	if skipped[0].Reason != "file not changed by the pull request" || skipped[1].Reason != "file added locally" {
+		t.Errorf("skipped = %+v", skipped)
+	}
+}
+
+func TestComment(t *testing.T) {
+	files := []FileDiff{{Path: "main.go", Patch: "@@ -3,2 +3,2 @@\n-line3\n-line4\n+LINE3\n+```go\n"}}
+	headLines := func(path string) ([]string, error) { return headFile, nil }
+
+	suggestions, _, err := Build(files, map[string]string{"main.go": prPatch}, headLines)
+	if err != nil || len(suggestions) != 1 {
+		t.Fatalf("Build() = %+v, %v", suggestions, err)
+	}
+
+	got := suggestions[0].Comment("  Simpler this way.\n")
+	want := github.DraftReviewComment{
+		Path:      "main.go",
+		Line:      4,
+		Side:      "RIGHT",
+		StartLine: 3,
+		StartSide: "RIGHT",
+		Body:      "Simpler this way.\n\n````suggestion\nLINE3\n```go\n````",
+	}
+	if got != want {
+		t.Errorf("Comment() = %+v, want %+v", got, want)
+	}
+
+	deletion := &Suggestion{Path: "main.go", StartLine: 5, EndLine: 5}
+	if body := deletion.Body(""); !strings.HasPrefix(body, "```suggestion\n```") {
+		t.Errorf("Body() of a deletion = %q", body)
+	}
+}
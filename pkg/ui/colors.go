@@ -23,9 +23,53 @@ func Colorize(color, text string) string {
 	return color + text + ColorReset
 }
 
-// ColorizeDiff applies syntax highlighting to diff hunks
-func ColorizeDiff(diff string) string {
+// ColorizeDiff applies syntax highlighting to diff hunks. When highlighting is available the
+// code of each line is highlighted for the language of path and only the +/- markers keep
+// the diff colors; otherwise whole lines are colored by change type.
+func ColorizeDiff(diff, path string) string {
 	lines := strings.Split(diff, "\n")
+
+	// Collect the code of every diff line so it can be highlighted in one pass. Patch
+	// headers before the first hunk (diff --git, ---, +++) are not code.
+	var code []string
+	var codeIndex []int
+	inHunk := false
+	for i, line := range lines {
+		if strings.HasPrefix(line, "@@") {
+			inHunk = true
+			continue
+		}
+		if !inHunk || len(line) == 0 || !strings.ContainsRune("+- ", rune(line[0])) {
+			continue
+		}
+		code = append(code, line[1:])
+		codeIndex = append(codeIndex, i)
+	}
+
+	highlighted, ok := highlight(code, path)
+	if !ok {
+		return colorizeDiffLines(lines)
+	}
+
+	coloredLines := strings.Split(colorizeDiffLines(lines), "\n")
+	for j, i := range codeIndex {
+		var marker string
+		switch lines[i][0] {
+		case '+':
+			marker = Colorize(ColorGreen, "+")
+		case '-':
+			marker = Colorize(ColorRed, "-")
+		default:
+			marker = " "
+		}
+		coloredLines[i] = marker + highlighted[j]
+	}
+
+	return strings.Join(coloredLines, "\n")
+}
+
+// colorizeDiffLines colors whole diff lines by change type
+func colorizeDiffLines(lines []string) string {
 	var coloredLines []string
 
 	for _, line := range lines {
@@ -49,8 +93,12 @@ func ColorizeDiff(diff string) string {
 	return strings.Join(coloredLines, "\n")
 }
 
-// ColorizeCode applies syntax highlighting to suggested code
-func ColorizeCode(code string) string {
+// ColorizeCode applies syntax highlighting to suggested code for the language of path,
+// falling back to plain green when highlighting is not available
+func ColorizeCode(code, path string) string {
+	if highlighted, ok := highlight(strings.Split(code, "\n"), path); ok {
+		return strings.Join(highlighted, "\n")
+	}
 	return Colorize(ColorGreen, code)
 }
 
@@ -106,3 +154,22 @@ func RenderMarkdown(text string) (string, error) {
 
 	return strings.TrimSpace(rendered), nil
 }
+
+// FormatCodeLines renders code lines numbered from first, syntax-highlighted according to
+// path, with the lines markStart to markEnd flagged in the gutter
+func FormatCodeLines(first int, lines []string, markStart, markEnd int, path string) string {
+	width := len(fmt.Sprintf("%d", first+len(lines)-1))
+	texts := HighlightLines(lines, path)
+
+	formatted := make([]string, 0, len(lines))
+	for i := range lines {
+		number := first + i
+		gutter := fmt.Sprintf("%*d │", width, number)
+		if number >= markStart && number <= markEnd {
+			formatted = append(formatted, Colorize(ColorYellow, "▶ "+gutter)+" "+texts[i])
+		} else {
+			formatted = append(formatted, Colorize(ColorGray, "  "+gutter)+" "+texts[i])
+		}
+	}
+	return strings.Join(formatted, "\n")
+}
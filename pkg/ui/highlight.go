@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma"
+	"github.com/alecthomas/chroma/formatters"
+	"github.com/alecthomas/chroma/lexers"
+	"github.com/alecthomas/chroma/styles"
+	"github.com/cli/go-gh/v2/pkg/term"
+)
+
+const (
+	// HighlightStyleEnv selects the chroma style used for syntax highlighting.
+	// Set it to "none" to disable highlighting.
+	HighlightStyleEnv = "GH_PRREVIEW_STYLE"
+
+	darkHighlightStyle  = "monokai"
+	lightHighlightStyle = "github"
+)
+
+var (
+	highlightStyle    string
+	highlightOnce     sync.Once
+	highlightEnabled  bool
+	highlightFormat   string
+	highlightStyleDef *chroma.Style
+)
+
+// SetHighlightStyle overrides the syntax highlighting style ("none" disables highlighting).
+// It must be called before any code is rendered.
+func SetHighlightStyle(style string) {
+	highlightStyle = style
+}
+
+// initHighlight decides once whether and how to syntax-highlight. Highlighting is only used
+// when writing colors to a terminal; otherwise the plain ANSI coloring is kept.
+func initHighlight() {
+	highlightOnce.Do(func() {
+		t := term.FromEnv()
+
+		style := highlightStyle
+		if style == "" {
+			style = os.Getenv(HighlightStyleEnv)
+		}
+		if style == "" {
+			style = darkHighlightStyle
+			if t.Theme() == "light" {
+				style = lightHighlightStyle
+			}
+		}
+
+		if strings.EqualFold(style, "none") || !t.IsTerminalOutput() || !t.IsColorEnabled() {
+			return
+		}
+
+		highlightEnabled = true
+		highlightStyleDef = styles.Get(style)
+		highlightFormat = "terminal256"
+		switch {
+		case t.IsTrueColorSupported():
+			highlightFormat = "terminal16m"
+		case !t.Is256ColorSupported():
+			highlightFormat = "terminal"
+		}
+	})
+}
+
+// DetectLanguage detects programming language from file extension
+func DetectLanguage(filePath string) string {
+	ext := filepath.Ext(filePath)
+	switch ext {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".js":
+		return "javascript"
+	case ".ts":
+		return "typescript"
+	case ".jsx":
+		return "javascript"
+	case ".tsx":
+		return "typescript"
+	case ".java":
+		return "java"
+	case ".rs":
+		return "rust"
+	case ".c":
+		return "c"
+	case ".cpp", ".cc", ".cxx":
+		return "cpp"
+	case ".h", ".hpp":
+		return "cpp"
+	case ".rb":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".sh":
+		return "bash"
+	case ".md":
+		return "markdown"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".json":
+		return "json"
+	default:
+		return "unknown"
+	}
+}
+
+// lexerFor returns the lexer for a file, using the detected language first and chroma's
+// file name matching for everything else
+func lexerFor(path string) chroma.Lexer {
+	if language := DetectLanguage(path); language != "unknown" {
+		if lexer := lexers.Get(language); lexer != nil {
+			return lexer
+		}
+	}
+	return lexers.Match(filepath.Base(path))
+}
+
+// highlight syntax-highlights lines of code for the file at path. The lines are tokenised
+// together so multi-line constructs are colored correctly. It returns false when
+// highlighting is disabled, no lexer matches or tokenising fails.
+func highlight(lines []string, path string) ([]string, bool) {
+	initHighlight()
+	if !highlightEnabled || len(lines) == 0 {
+		return lines, false
+	}
+
+	lexer := lexerFor(path)
+	if lexer == nil {
+		return lines, false
+	}
+	lexer = chroma.Coalesce(lexer)
+
+	iterator, err := lexer.Tokenise(nil, strings.Join(lines, "\n")+"\n")
+	if err != nil {
+		return lines, false
+	}
+
+	tokenLines := chroma.SplitTokensIntoLines(iterator.Tokens())
+	if len(tokenLines) != len(lines) {
+		return lines, false
+	}
+
+	formatter := formatters.Get(highlightFormat)
+	highlighted := make([]string, 0, len(lines))
+	for _, tokens := range tokenLines {
+		// Drop the line break so it is not wrapped in color codes
+		last := len(tokens) - 1
+		tokens[last].Value = strings.TrimSuffix(tokens[last].Value, "\n")
+
+		var buf bytes.Buffer
+		if err := formatter.Format(&buf, highlightStyleDef, chroma.Literator(tokens...)); err != nil {
+			return lines, false
+		}
+		highlighted = append(highlighted, buf.String())
+	}
+	return highlighted, true
+}
+
+// HighlightLines syntax-highlights lines of code using the lexer matching the file. When
+// highlighting is not possible, the lines are returned unchanged.
+func HighlightLines(lines []string, path string) []string {
+	highlighted, _ := highlight(lines, path)
+	return highlighted
+}
@@ -0,0 +1,41 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// RunHook runs command through the shell with the event described in its environment.
+// The command's output goes to the terminal.
+func RunHook(command string, event Event, repo string, prNumber int) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Env = append(os.Environ(), event.Env(repo, prNumber)...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("hook %q failed: %w", command, err)
+	}
+	return nil
+}
+
+// Notify shows a desktop notification, using notify-send on Linux and osascript on macOS
+func Notify(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %q with title %q", message, title)
+		cmd = exec.Command("osascript", "-e", script)
+	default:
+		if _, err := exec.LookPath("notify-send"); err != nil {
+			return fmt.Errorf("notify-send not found: %w", err)
+		}
+		cmd = exec.Command("notify-send", "--app-name=gh-prreview", title, message)
+	}
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to send notification: %w: %s", err, output)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// Package watch detects what changed in the review threads of a pull request between two
+// polls and reports it as events.
+package watch
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+// EventType is the kind of change seen in a review thread
+type EventType string
+
+const (
+	EventNewComment EventType = "new_comment"
+	EventNewReply   EventType = "new_reply"
+	EventResolved   EventType = "resolved"
+	EventUnresolved EventType = "unresolved"
+)
+
+// Event is a change in a review thread. Reply is only set for EventNewReply.
+type Event struct {
+	Type   EventType
+	Thread *github.ReviewComment
+	Reply  *github.ThreadComment
+}
+
+// Author returns who wrote the new comment or reply; empty for resolution changes
+func (e Event) Author() string {
+	switch e.Type {
+	case EventNewComment:
+		return e.Thread.Author
+	case EventNewReply:
+		return e.Reply.Author
+	default:
+		return ""
+	}
+}
+
+// Body returns the text of the new comment or reply; empty for resolution changes
+func (e Event) Body() string {
+	switch e.Type {
+	case EventNewComment:
+		return e.Thread.Body
+	case EventNewReply:
+		return e.Reply.Body
+	default:
+		return ""
+	}
+}
+
+// URL links to the new reply, or to the thread
+func (e Event) URL() string {
+	if e.Reply != nil && e.Reply.HTMLURL != "" {
+		return e.Reply.HTMLURL
+	}
+	return e.Thread.HTMLURL
+}
+
+// Env returns the GH_PRREVIEW_* environment variables describing the event to a hook command
+func (e Event) Env(repo string, prNumber int) []string {
+	commentID := e.Thread.ID
+	if e.Reply != nil {
+		commentID = e.Reply.ID
+	}
+
+	line := e.Thread.Line
+	if line == 0 {
+		line = e.Thread.OriginalLine
+	}
+
+	return []string{
+		"GH_PRREVIEW_EVENT=" + string(e.Type),
+		"GH_PRREVIEW_REPO=" + repo,
+		"GH_PRREVIEW_PR=" + strconv.Itoa(prNumber),
+		"GH_PRREVIEW_THREAD_ID=" + e.Thread.ThreadID,
+		"GH_PRREVIEW_COMMENT_ID=" + strconv.FormatInt(commentID, 10),
+		"GH_PRREVIEW_PATH=" + e.Thread.Path,
+		"GH_PRREVIEW_LINE=" + strconv.Itoa(line),
+		"GH_PRREVIEW_LOCATION=" + e.Thread.Location(),
+		"GH_PRREVIEW_AUTHOR=" + e.Author(),
+		"GH_PRREVIEW_URL=" + e.URL(),
+		"GH_PRREVIEW_BODY=" + e.Body(),
+	}
+}
+
+// Summary is a one-line description of the event
+func (e Event) Summary() string {
+	switch e.Type {
+	case EventNewComment:
+		return fmt.Sprintf("New comment by @%s on %s", e.Thread.Author, e.Thread.Location())
+	case EventNewReply:
+		return fmt.Sprintf("Reply by @%s on %s", e.Reply.Author, e.Thread.Location())
+	case EventResolved:
+		return fmt.Sprintf("Thread on %s resolved", e.Thread.Location())
+	case EventUnresolved:
+		return fmt.Sprintf("Thread on %s unresolved", e.Thread.Location())
+	default:
+		return string(e.Type)
+	}
+}
+
+// Snapshot is the state of the review threads of a pull request at one poll
+type Snapshot struct {
+	threads []*github.ReviewComment
+	byID    map[int64]*github.ReviewComment
+}
+
+// NewSnapshot records the state of the review threads
+func NewSnapshot(threads []*github.ReviewComment) *Snapshot {
+	snapshot := &Snapshot{
+		threads: threads,
+		byID:    make(map[int64]*github.ReviewComment, len(threads)),
+	}
+	for _, thread := range threads {
+		snapshot.byID[thread.ID] = thread
+	}
+	return snapshot
+}
+
+// Len returns the number of threads in the snapshot
+func (s *Snapshot) Len() int {
+	return len(s.threads)
+}
+
+// Diff returns what changed from prev to next, in the order of the threads of next: a new
+// thread is reported once as a new comment (its replies included), and known threads report
+// their new replies then a change of resolution.
+func Diff(prev, next *Snapshot) []Event {
+	var events []Event
+	for _, thread := range next.threads {
+		old, ok := prev.byID[thread.ID]
+		if !ok {
+			events = append(events, Event{Type: EventNewComment, Thread: thread})
+			continue
+		}
+
+		seen := make(map[int64]bool, len(old.ThreadComments))
+		for _, reply := range old.ThreadComments {
+			seen[reply.ID] = true
+		}
+		for i := range thread.ThreadComments {
+			if reply := &thread.ThreadComments[i]; !seen[reply.ID] {
+				events = append(events, Event{Type: EventNewReply, Thread: thread, Reply: reply})
+			}
+		}
+
+		switch {
+		case thread.IsResolved() && !old.IsResolved():
+			events = append(events, Event{Type: EventResolved, Thread: thread})
+		case !thread.IsResolved() && old.IsResolved():
+			events = append(events, Event{Type: EventUnresolved, Thread: thread})
+		}
+	}
+	return events
+}
@@ -0,0 +1,133 @@
+package watch
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/chmouel/gh-prreview/pkg/github"
+)
+
+func thread(id int64, resolved bool, replyIDs ...int64) *github.ReviewComment {
+	comment := &github.ReviewComment{
+		ID:       id,
+		ThreadID: fmt.Sprintf("PRRT_%d", id),
+		Path:     "main.go",
+		Line:     int(id),
+		Author:   "alice",
+		Body:     "comment",
+		Resolved: resolved,
+	}
+	for _, replyID := range replyIDs {
+		comment.ThreadComments = append(comment.ThreadComments, github.ThreadComment{ID: replyID, Author: "bob", Body: "reply"})
+	}
+	return comment
+}
+
+type eventKey struct {
+	Type    EventType
+	Thread  int64
+	ReplyID int64
+}
+
+func keys(events []Event) []eventKey {
+	result := make([]eventKey, 0, len(events))
+	for _, event := range events {
+		key := eventKey{Type: event.Type, Thread: event.Thread.ID}
+		if event.Reply != nil {
+			key.ReplyID = event.Reply.ID
+		}
+		result = append(result, key)
+	}
+	return result
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name string
+		prev []*github.ReviewComment
+		next []*github.ReviewComment
+		want []eventKey
+	}{
+		{
+			name: "nothing changed",
+			prev: []*github.ReviewComment{thread(1, false, 10)},
+			next: []*github.ReviewComment{thread(1, false, 10)},
+			want: []eventKey{},
+		},
+		{
+			name: "new thread with replies is a single event",
+			prev: []*github.ReviewComment{thread(1, false)},
+			next: []*github.ReviewComment{thread(1, false), thread(2, false, 20)},
+			want: []eventKey{{Type: EventNewComment, Thread: 2}},
+		},
+		{
+			name: "new replies",
+			prev: []*github.ReviewComment{thread(1, false, 10)},
+			next: []*github.ReviewComment{thread(1, false, 10, 11, 12)},
+			want: []eventKey{
+				{Type: EventNewReply, Thread: 1, ReplyID: 11},
+				{Type: EventNewReply, Thread: 1, ReplyID: 12},
+			},
+		},
+		{
+			name: "reply then resolution",
+			prev: []*github.ReviewComment{thread(1, false)},
+			next: []*github.ReviewComment{thread(1, true, 10)},
+			want: []eventKey{
+				{Type: EventNewReply, Thread: 1, ReplyID: 10},
+				{Type: EventResolved, Thread: 1},
+			},
+		},
+		{
+			name: "unresolved",
+			prev: []*github.ReviewComment{thread(1, true), thread(2, false)},
+			next: []*github.ReviewComment{thread(1, false), thread(2, false)},
+			want: []eventKey{{Type: EventUnresolved, Thread: 1}},
+		},
+		{
+			name: "deleted thread is ignored",
+			prev: []*github.ReviewComment{thread(1, false), thread(2, false)},
+			next: []*github.ReviewComment{thread(2, false)},
+			want: []eventKey{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := keys(Diff(NewSnapshot(tt.prev), NewSnapshot(tt.next)))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventEnv(t *testing.T) {
+	comment := thread(1, false, 10)
+	comment.HTMLURL = "https://github.com/o/r/pull/3#discussion_r1"
+	comment.ThreadComments[0].HTMLURL = "https://github.com/o/r/pull/3#discussion_r10"
+
+	env := Event{Type: EventNewReply, Thread: comment, Reply: &comment.ThreadComments[0]}.Env("o/r", 3)
+	for _, want := range []string{
+		"GH_PRREVIEW_EVENT=new_reply",
+		"GH_PRREVIEW_REPO=o/r",
+		"GH_PRREVIEW_PR=3",
+		"GH_PRREVIEW_COMMENT_ID=10",
+		"GH_PRREVIEW_PATH=main.go",
+		"GH_PRREVIEW_LINE=1",
+		"GH_PRREVIEW_AUTHOR=bob",
+		"GH_PRREVIEW_URL=https://github.com/o/r/pull/3#discussion_r10",
+		"GH_PRREVIEW_BODY=reply",
+	} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Env() is missing %q: %v", want, env)
+		}
+	}
+
+	resolved := Event{Type: EventResolved, Thread: comment}
+	if resolved.Author() != "" || resolved.URL() != comment.HTMLURL {
+		t.Errorf("resolution event should link to the thread, got author %q url %q", resolved.Author(), resolved.URL())
+	}
+}